@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +15,15 @@ type Config struct {
 	ServerPort string
 	DBConfig   DatabaseConfig
 	JWTSecret  string
+
+	// PantryAlertDays is how many days ahead pantry items are reported as
+	// expiring; the check runs every PantryAlertInterval.
+	PantryAlertDays     int
+	PantryAlertInterval time.Duration
+
+	// AdminEmails are promoted to admin at startup, so a fresh install has
+	// someone who can hand out roles.
+	AdminEmails []string
 }
 
 type DatabaseConfig struct {
@@ -39,6 +52,21 @@ func LoadConfig() (*Config, error) {
 		JWTSecret: getEnv("JWT_SECRET", "your-super-secret-key"),
 	}
 
+	config.PantryAlertDays, err = strconv.Atoi(getEnv("PANTRY_ALERT_DAYS", "3"))
+	if err != nil || config.PantryAlertDays < 0 {
+		return nil, fmt.Errorf("invalid PANTRY_ALERT_DAYS: %q", os.Getenv("PANTRY_ALERT_DAYS"))
+	}
+	config.PantryAlertInterval, err = time.ParseDuration(getEnv("PANTRY_ALERT_INTERVAL", "1h"))
+	if err != nil || config.PantryAlertInterval <= 0 {
+		return nil, fmt.Errorf("invalid PANTRY_ALERT_INTERVAL: %q", os.Getenv("PANTRY_ALERT_INTERVAL"))
+	}
+
+	for _, email := range strings.Split(getEnv("ADMIN_EMAILS", ""), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			config.AdminEmails = append(config.AdminEmails, email)
+		}
+	}
+
 	return config, nil
 }
 
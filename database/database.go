@@ -3,6 +3,7 @@ package database
 import (
 	"foodapp/config"
 	"foodapp/models"
+	"foodapp/utils"
 	"log"
 
 	"github.com/glebarez/sqlite"
@@ -42,10 +43,185 @@ func MigrateDB() {
 		&models.Ingredient{},
 		&models.DishIngredient{},
 		&models.FavoriteDish{},
+		&models.FavoriteCollection{},
 		&models.Cart{},
+		&models.CartDishContribution{},
 		&models.Statistics{},
+		&models.DishStep{},
+		&models.DishStepIngredient{},
+		&models.Tag{},
+		&models.DishTag{},
+		&models.IngredientAllergen{},
+		&models.UserAllergen{},
+		&models.UserDiet{},
+		&models.IngredientSubstitution{},
+		&models.IngredientSynonym{},
+		&models.DishReview{},
+		&models.ShareLink{},
+		&models.ShareAccess{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.HouseholdInvite{},
+		&models.Purchase{},
+		&models.PurchaseItem{},
+		&models.StoreProfile{},
+		&models.StoreSection{},
+		&models.IngredientPrice{},
+		&models.PantryItem{},
+		&models.Notification{},
+		&models.MealPlanEntry{},
+		&models.MealPlanFeed{},
+		&models.NutritionGoal{},
+		&models.DataMigration{},
 	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
+
+	if err := MigrateDishSteps(DB); err != nil {
+		log.Fatalf("Failed to migrate dish steps: %v", err)
+	}
+
+	if err := MigrateIngredientNames(DB); err != nil {
+		log.Fatalf("Failed to migrate ingredient names: %v", err)
+	}
+
+	if err := MigrateFavoriteCollections(DB); err != nil {
+		log.Fatalf("Failed to migrate favorite collections: %v", err)
+	}
+
+	if err := MigrateCartAddedBy(DB); err != nil {
+		log.Fatalf("Failed to migrate cart lines: %v", err)
+	}
 	log.Println("Database migrated successfully")
 }
+
+// MigrateDishSteps splits the free-text Instruction of every dish that has no
+// steps yet into ordered DishStep rows. It runs once: dishes created since get
+// their steps from CreateDish, and a dish whose steps were all deleted keeps
+// none.
+func MigrateDishSteps(db *gorm.DB) error {
+	return runOnce(db, "dish_steps", func(tx *gorm.DB) error {
+		var dishes []models.Dish
+		if err := tx.Select("id", "instruction").
+			Where("instruction <> ''").
+			Where("id NOT IN (?)", tx.Model(&models.DishStep{}).Select("dish_id")).
+			Find(&dishes).Error; err != nil {
+			return err
+		}
+
+		for _, dish := range dishes {
+			if err := CreateInstructionSteps(tx, dish.ID, dish.Instruction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateInstructionSteps adds the steps SplitInstruction finds in instruction
+// to a dish, in order.
+func CreateInstructionSteps(tx *gorm.DB, dishID uint, instruction string) error {
+	for i, text := range utils.SplitInstruction(instruction) {
+		step := models.DishStep{
+			DishID:   dishID,
+			Position: i + 1,
+			Text:     text,
+		}
+		if err := tx.Create(&step).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runOnce applies migrate in a transaction unless a migration called name has
+// already been recorded, and records it.
+func runOnce(db *gorm.DB, name string, migrate func(tx *gorm.DB) error) error {
+	var count int64
+	if err := db.Model(&models.DataMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&models.DataMigration{Name: name}).Error
+	})
+}
+
+// MigrateIngredientNames fills in NormalizedName for ingredients created
+// before names were normalized.
+func MigrateIngredientNames(db *gorm.DB) error {
+	var ingredients []models.Ingredient
+	if err := db.Select("id", "name").Where("normalized_name IS NULL OR normalized_name = ''").Find(&ingredients).Error; err != nil {
+		return err
+	}
+
+	for _, ingredient := range ingredients {
+		normalized := utils.NormalizeIngredientName(ingredient.Name)
+		if err := db.Model(&models.Ingredient{}).Where("id = ?", ingredient.ID).Update("normalized_name", normalized).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrateFavoriteCollections moves favorites saved before collections existed
+// into a default collection per user.
+func MigrateFavoriteCollections(db *gorm.DB) error {
+	var userIDs []uint
+	if err := db.Model(&models.FavoriteDish{}).Distinct("user_id").Where("collection_id IS NULL OR collection_id = 0").Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var collection models.FavoriteCollection
+			result := tx.Where("user_id = ? AND is_default = ?", userID, true).Limit(1).Find(&collection)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				collection = models.FavoriteCollection{
+					UserID:    userID,
+					Name:      models.DefaultCollectionName,
+					IsDefault: true,
+				}
+				if err := tx.Create(&collection).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Model(&models.FavoriteDish{}).
+				Where("user_id = ? AND (collection_id IS NULL OR collection_id = 0)", userID).
+				Update("collection_id", collection.ID).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrateCartAddedBy credits cart lines created before carts could be shared
+// to the user whose cart they are in.
+func MigrateCartAddedBy(db *gorm.DB) error {
+	return db.Model(&models.Cart{}).
+		Where("added_by IS NULL OR added_by = 0").
+		Update("added_by", gorm.Expr("user_id")).Error
+}
+
+// PromoteAdmins gives the admin role to the users with the given e-mail
+// addresses. Addresses without an account are skipped.
+func PromoteAdmins(emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	return DB.Model(&models.User{}).Where("LOWER(email) IN ?", emails).Update("role", models.RoleAdmin).Error
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"foodapp/database"
+	"foodapp/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	profileModeFlag = "flag"
+	profileModeHide = "hide"
+	profileModeOff  = "off"
+)
+
+// @Summary Get allergens
+// @Description Get the list of allergen codes that can be declared (EU 14)
+// @Tags allergens
+// @Accept json
+// @Produce json
+// @Success 200 {array} string
+// @Router /allergens [get]
+func GetAllergens(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.Allergens)
+}
+
+// @Summary Set ingredient allergens
+// @Description Replace the allergens declared for an ingredient
+// @Tags allergens
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param request body models.AllergensRequest true "Allergen codes"
+// @Success 200 {object} models.IngredientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/allergens [put]
+func SetIngredientAllergens(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var req models.AllergensRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	allergens, err := normalizeAllergens(req.Allergens)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceIngredientAllergens(tx, ingredient.ID, allergens)
+	}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update allergens",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.IngredientResponse{
+		ID:        ingredient.ID,
+		Name:      ingredient.Name,
+		Allergens: allergens,
+	})
+}
+
+// @Summary Get dish allergens
+// @Description Get the allergens of a dish, derived from its ingredients
+// @Tags allergens
+// @Accept json
+// @Produce json
+// @Param id path int true "Dish ID"
+// @Success 200 {array} string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /dishes/{id}/allergens [get]
+func GetDishAllergens(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	allergens := dishAllergens([]uint{dish.ID})[dish.ID]
+	if allergens == nil {
+		allergens = []string{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(allergens)
+}
+
+// @Summary Get dietary profile
+// @Description Get the current user's allergies and diets
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.DietaryProfile
+// @Router /users/profile/dietary [get]
+func GetDietaryProfile(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	return c.Status(fiber.StatusOK).JSON(loadDietaryProfile(userID))
+}
+
+// @Summary Update dietary profile
+// @Description Replace the current user's allergies and diets
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.DietaryProfile true "Dietary profile"
+// @Success 200 {object} models.DietaryProfile
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/profile/dietary [put]
+func UpdateDietaryProfile(c *fiber.Ctx) error {
+	var req models.DietaryProfile
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	allergens, err := normalizeAllergens(req.Allergens)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var diets []string
+	for _, diet := range req.Diets {
+		if diet = normalizeTagName(diet); diet != "" {
+			diets = append(diets, diet)
+		}
+	}
+	diets = uniqueStrings(diets)
+
+	if len(diets) > 0 {
+		var count int64
+		database.DB.Model(&models.Tag{}).Where("type = ? AND name IN ?", models.TagTypeDiet, diets).Count(&count)
+		if int(count) != len(diets) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Unknown diet",
+			})
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserAllergen{}).Error; err != nil {
+			return err
+		}
+		for _, allergen := range allergens {
+			if err := tx.Create(&models.UserAllergen{UserID: userID, Allergen: allergen}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserDiet{}).Error; err != nil {
+			return err
+		}
+		for _, diet := range diets {
+			if err := tx.Create(&models.UserDiet{UserID: userID, Diet: diet}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update dietary profile",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(loadDietaryProfile(userID))
+}
+
+// normalizeAllergens lower-cases, de-duplicates and validates allergen codes.
+func normalizeAllergens(values []string) ([]string, error) {
+	known := make(map[string]bool, len(models.Allergens))
+	for _, allergen := range models.Allergens {
+		known[allergen] = true
+	}
+
+	allergens := []string{}
+	for _, value := range values {
+		allergen := strings.ToLower(strings.TrimSpace(value))
+		if allergen == "" {
+			continue
+		}
+		if !known[allergen] {
+			return nil, errors.New("Unknown allergen: " + value)
+		}
+		allergens = append(allergens, allergen)
+	}
+
+	allergens = uniqueStrings(allergens)
+	sort.Strings(allergens)
+	return allergens, nil
+}
+
+func replaceIngredientAllergens(tx *gorm.DB, ingredientID uint, allergens []string) error {
+	if err := tx.Where("ingredient_id = ?", ingredientID).Delete(&models.IngredientAllergen{}).Error; err != nil {
+		return err
+	}
+
+	for _, allergen := range allergens {
+		link := models.IngredientAllergen{
+			IngredientID: ingredientID,
+			Allergen:     allergen,
+		}
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadIngredientAllergens(ingredientID uint) []string {
+	var allergens []string
+	database.DB.Model(&models.IngredientAllergen{}).
+		Where("ingredient_id = ?", ingredientID).
+		Order("allergen").
+		Pluck("allergen", &allergens)
+	return allergens
+}
+
+// dishAllergens derives the allergens of each dish from its ingredients.
+func dishAllergens(dishIDs []uint) map[uint][]string {
+	allergens := make(map[uint][]string)
+	if len(dishIDs) == 0 {
+		return allergens
+	}
+
+	var rows []struct {
+		DishID   uint
+		Allergen string
+	}
+	database.DB.Model(&models.DishIngredient{}).
+		Select("DISTINCT dish_ingredients.dish_id, ingredient_allergens.allergen").
+		Joins("JOIN ingredient_allergens ON ingredient_allergens.ingredient_id = dish_ingredients.ingredient_id").
+		Where("dish_ingredients.dish_id IN ?", dishIDs).
+		Order("ingredient_allergens.allergen").
+		Scan(&rows)
+
+	for _, row := range rows {
+		allergens[row.DishID] = append(allergens[row.DishID], row.Allergen)
+	}
+	return allergens
+}
+
+func loadDietaryProfile(userID uint) models.DietaryProfile {
+	profile := models.DietaryProfile{
+		Allergens: []string{},
+		Diets:     []string{},
+	}
+
+	database.DB.Model(&models.UserAllergen{}).Where("user_id = ?", userID).Order("allergen").Pluck("allergen", &profile.Allergens)
+	database.DB.Model(&models.UserDiet{}).Where("user_id = ?", userID).Order("diet").Pluck("diet", &profile.Diets)
+	return profile
+}
+
+// parseProfileMode reads ?profile=flag|hide|off; flag is the default.
+func parseProfileMode(c *fiber.Ctx) (string, error) {
+	switch mode := c.Query("profile", profileModeFlag); mode {
+	case profileModeFlag, profileModeHide, profileModeOff:
+		return mode, nil
+	default:
+		return "", errors.New("profile must be flag, hide or off")
+	}
+}
+
+// applyDietaryProfile fills in each dish's allergens and, for a signed-in
+// caller, flags or drops dishes that conflict with their dietary profile.
+func applyDietaryProfile(c *fiber.Ctx, dishes []models.DishWithIngredients, mode string) []models.DishWithIngredients {
+	dishIDs := make([]uint, 0, len(dishes))
+	for _, dish := range dishes {
+		dishIDs = append(dishIDs, dish.Dish.ID)
+	}
+	allergens := dishAllergens(dishIDs)
+
+	userID, signedIn := c.Locals("userID").(uint)
+	var profile models.DietaryProfile
+	if signedIn && mode != profileModeOff {
+		profile = loadDietaryProfile(userID)
+	}
+
+	var filtered []models.DishWithIngredients
+	for _, dish := range dishes {
+		dish.Allergens = allergens[dish.Dish.ID]
+		dish.Conflicts = dietaryConflicts(dish, profile)
+
+		if dish.Conflicts != nil && mode == profileModeHide {
+			continue
+		}
+		filtered = append(filtered, dish)
+	}
+	return filtered
+}
+
+func dietaryConflicts(dish models.DishWithIngredients, profile models.DietaryProfile) *models.DietaryConflicts {
+	var conflicts models.DietaryConflicts
+
+	avoid := make(map[string]bool, len(profile.Allergens))
+	for _, allergen := range profile.Allergens {
+		avoid[allergen] = true
+	}
+	for _, allergen := range dish.Allergens {
+		if avoid[allergen] {
+			conflicts.Allergens = append(conflicts.Allergens, allergen)
+		}
+	}
+
+	tagged := make(map[string]bool, len(dish.Tags))
+	for _, tag := range dish.Tags {
+		tagged[tag.Name] = true
+	}
+	for _, diet := range profile.Diets {
+		if !tagged[diet] {
+			conflicts.Diets = append(conflicts.Diets, diet)
+		}
+	}
+
+	if len(conflicts.Allergens) == 0 && len(conflicts.Diets) == 0 {
+		return nil
+	}
+	return &conflicts
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"foodapp/models"
+	"foodapp/service"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// cartEventsKeepAlive is how often an idle event stream gets a comment line,
+// so proxies keep the connection open and dead clients are noticed.
+var cartEventsKeepAlive = 25 * time.Second
+
+// @Summary Stream cart changes
+// @Description Server-sent events stream of line_added, line_updated, line_removed, cart_cleared and trip_finished events for the active cart. The stream follows the cart that was active when it was opened.
+// @Tags cart
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Success 200 {object} models.CartEvent
+// @Router /cart/events [get]
+func StreamCartEvents(c *fiber.Ctx) error {
+	owner := activeCart(c)
+	events, cancel := service.CartEvents.Subscribe(owner.topic())
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		keepAlive := time.NewTicker(cartEventsKeepAlive)
+		defer keepAlive.Stop()
+
+		fmt.Fprint(w, ": connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return nil
+}
+
+// topic names the event stream of this cart.
+func (o cartOwner) topic() string {
+	if o.HouseholdID != nil {
+		return "household:" + strconv.FormatUint(uint64(*o.HouseholdID), 10)
+	}
+	return "user:" + strconv.FormatUint(uint64(o.UserID), 10)
+}
+
+func cartLineEvent(eventType string, line models.Cart) models.CartEvent {
+	return models.CartEvent{
+		Type:         eventType,
+		LineID:       line.ID,
+		IngredientID: line.IngredientID,
+		Quantity:     line.Quantity,
+		Unit:         line.Unit,
+		Checked:      line.Checked,
+	}
+}
+
+// pendingCartEvent is an event for a cart, held until the transaction that
+// changed the cart commits.
+type pendingCartEvent struct {
+	owner cartOwner
+	event models.CartEvent
+}
+
+// publishCartLine tells the clients watching the cart that a line changed.
+func publishCartLine(owner cartOwner, eventType string, line models.Cart) {
+	publishCartEvent(owner, cartLineEvent(eventType, line))
+}
+
+func publishCartEvent(owner cartOwner, event models.CartEvent) {
+	event.HouseholdID = owner.HouseholdID
+	event.ActorID = owner.UserID
+	event.At = time.Now()
+	service.CartEvents.Publish(owner.topic(), event)
+}
@@ -2,9 +2,15 @@ package handlers
 
 import (
 	"encoding/base64"
+	"errors"
 	"foodapp/database"
 	"foodapp/models"
+	"foodapp/service"
+	"foodapp/utils"
 	"gorm.io/gorm"
+	"math"
+	"sort"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -33,40 +39,7 @@ func AddIngredientsToCart(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	req.UserID = userID
 
-	var existingCartItem models.Cart
-	result := database.DB.Where("user_id = ? AND ingredient_id = ?", userID, req.IngredientID).First(&existingCartItem)
-
-	if result.RowsAffected > 0 {
-		existingCartItem.Quantity += req.Quantity
-		if result := database.DB.Save(&existingCartItem); result.Error != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update cart",
-			})
-		}
-
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"message": "Cart updated successfully",
-			"id":      existingCartItem.ID,
-		})
-	}
-
-	// Add new cart item
-	cartItem := models.Cart{
-		UserID:       userID,
-		IngredientID: req.IngredientID,
-		Quantity:     req.Quantity,
-	}
-
-	if result := database.DB.Create(&cartItem); result.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add to cart",
-		})
-	}
-
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Ingredient added to cart successfully",
-		"id":      cartItem.ID,
-	})
+	return addIngredientToCart(c, activeCart(c), req)
 }
 
 // @Summary Get user's cart
@@ -76,13 +49,20 @@ func AddIngredientsToCart(c *fiber.Ctx) error {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param email query string false "User email"
+// @Param group_by query string false "Set to section to group lines by grocery section"
+// @Param store query int false "Store profile whose section order and prices to use, defaults to the user's default store"
+// @Param total query bool false "Wrap the cart with its estimated cost"
+// @Param currency query string false "Currency of the estimate (default EUR)"
 // @Success 200 {array} models.CartResponse
+// @Success 200 {array} models.CartSectionResponse
+// @Success 200 {object} models.CartWithTotal
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /cart/get [get]
 func GetUserCart(c *fiber.Ctx) error {
 	email := c.Query("q")
-	var userID uint
+	var owner cartOwner
 
 	if email != "" {
 		// Find user by email
@@ -92,44 +72,108 @@ func GetUserCart(c *fiber.Ctx) error {
 				"error": "User not found",
 			})
 		}
-		userID = user.ID
+		owner = personalCart(user.ID)
 	} else {
-		// Get user ID from context (set by auth middleware)
-		userID = c.Locals("userID").(uint)
+		// Use the active cart of the user from context (set by auth middleware)
+		owner = activeCart(c)
 	}
 
-	// Get user's cart items
-	var cartItems []models.Cart
-	if result := database.DB.Where("user_id = ?", userID).Find(&cartItems); result.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch cart items",
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "section" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid group_by",
 		})
 	}
 
-	var response []models.CartResponse
-	for _, item := range cartItems {
-		var ingredient models.Ingredient
-		database.DB.First(&ingredient, item.IngredientID)
+	withTotal := c.QueryBool("total")
+	currency, err := parseCurrencyQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid currency",
+		})
+	}
 
-		cartResponse := models.CartResponse{
-			ID:       item.ID,
-			UserID:   item.UserID,
-			Quantity: item.Quantity,
+	var store *models.StoreProfile
+	if groupBy == "section" || withTotal {
+		var ok bool
+		if store, ok, err = cartStore(c); !ok {
+			return err
 		}
+	}
 
-		// Set ingredient details
-		cartResponse.Ingredient.ID = ingredient.ID
-		cartResponse.Ingredient.Name = ingredient.Name
+	response, err := loadCart(owner)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch cart items",
+		})
+	}
 
-		// Convert image to base64 if exists
-		if len(ingredient.Image) > 0 {
-			cartResponse.Ingredient.Image = base64.StdEncoding.EncodeToString(ingredient.Image)
+	if !withTotal {
+		if groupBy == "section" {
+			return c.Status(fiber.StatusOK).JSON(groupCartBySection(response, storeSectionOrder(store)))
 		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
 
-		response = append(response, cartResponse)
+	storeName := ""
+	if store != nil {
+		storeName = store.Name
+	}
+	cart := models.CartWithTotal{Estimate: estimateCartCost(response, currency, storeName)}
+	if groupBy == "section" {
+		cart.Sections = groupCartBySection(response, storeSectionOrder(store))
+	} else {
+		cart.Lines = response
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	return c.Status(fiber.StatusOK).JSON(cart)
+}
+
+// @Summary Export cart
+// @Description Download the active cart as a shopping list grouped by grocery section, as PDF, plain text, Markdown or CSV. The PDF uses the standard Helvetica fonts, which cover Latin-1 only; other characters, such as Cyrillic or CJK, are printed as a question mark
+// @Tags cart
+// @Produce application/pdf
+// @Produce plain
+// @Produce text/markdown
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param format query string false "pdf, txt, md or csv (default txt)"
+// @Param store query int false "Store profile whose section order to use, defaults to the user's default store"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/export [get]
+func ExportCart(c *fiber.Ctx) error {
+	format := c.Query("format", "txt")
+
+	store, ok, err := cartStore(c)
+	if !ok {
+		return err
+	}
+
+	response, err := loadCart(activeCart(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch cart items",
+		})
+	}
+
+	export, err := service.ExportCart(groupCartBySection(response, storeSectionOrder(store)), format)
+	if errors.Is(err, service.ErrUnknownExportFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export cart",
+		})
+	}
+
+	c.Attachment("shopping-list." + export.Extension)
+	c.Set(fiber.HeaderContentType, export.ContentType)
+	return c.Status(fiber.StatusOK).Send(export.Body)
 }
 
 func RemoveIngredientsCart(c *fiber.Ctx) error {
@@ -142,29 +186,21 @@ func RemoveIngredientsCart(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	req.UserID = userID
 
-	var result *gorm.DB
-	if result = database.DB.Where("user_id = ?", req.UserID).Where("ingredient_id = ?", req.IngredientID).Delete(&models.Cart{}); result.Error != nil {
+	return removeIngredientFromCart(c, activeCart(c), req.IngredientID)
+}
+
+func RemoveAllIngredientsCart(c *fiber.Ctx) error {
+	owner := activeCart(c)
+
+	lineIDs := owner.lines(database.DB.Model(&models.Cart{})).Select("id")
+	if err := database.DB.Where("cart_id IN (?)", lineIDs).Delete(&models.CartDishContribution{}).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete ingredients from cart",
 		})
 	}
 
-	if result.RowsAffected == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Not found",
-		})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Deleted successfully",
-	})
-}
-
-func RemoveAllIngredientsCart(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
 	var result *gorm.DB
-	if result = database.DB.Where("user_id = ?", userID).Delete(&models.Cart{}); result.Error != nil {
+	if result = owner.lines(database.DB).Delete(&models.Cart{}); result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete ingredients from cart",
 		})
@@ -176,6 +212,8 @@ func RemoveAllIngredientsCart(c *fiber.Ctx) error {
 		})
 	}
 
+	publishCartEvent(owner, models.CartEvent{Type: models.CartEventCleared})
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Deleted successfully",
 	})
@@ -189,29 +227,36 @@ func UpdateQuantityCart(c *fiber.Ctx) error {
 		})
 	}
 
+	owner := activeCart(c)
+	req.UserID = owner.UserID
+
 	var existingCartItem models.Cart
-	result := database.DB.Where("user_id = ? AND ingredient_id = ?", req.UserID, req.IngredientID).First(&existingCartItem)
+	result := owner.lines(database.DB).Where("ingredient_id = ?", req.IngredientID).First(&existingCartItem)
 
 	if result.RowsAffected > 0 {
 		newQuantity := existingCartItem.Quantity + req.Quantity
 
 		if newQuantity <= 0 {
+			database.DB.Where("cart_id = ?", existingCartItem.ID).Delete(&models.CartDishContribution{})
 			if delErr := database.DB.Delete(&existingCartItem).Error; delErr != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 					"error": "Failed to remove item from cart",
 				})
 			}
+			existingCartItem.Quantity = 0
+			publishCartLine(owner, models.CartEventLineRemoved, existingCartItem)
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
 				"message": "Ingredient removed from cart",
 			})
 		}
 
-		existingCartItem.Quantity = newQuantity
+		growCartLine(&existingCartItem, req.Quantity)
 		if result := database.DB.Save(&existingCartItem); result.Error != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to update cart",
 			})
 		}
+		publishCartLine(owner, models.CartEventLineUpdated, existingCartItem)
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"message": "Cart updated successfully",
@@ -225,20 +270,763 @@ func UpdateQuantityCart(c *fiber.Ctx) error {
 		})
 	}
 
-	cartItem := models.Cart{
-		UserID:       req.UserID,
-		IngredientID: req.IngredientID,
-		Quantity:     req.Quantity,
+	cartItem := owner.newLine(req.IngredientID, req.Quantity, "")
+
+	if result := database.DB.Create(&cartItem); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add to cart",
+		})
+	}
+	publishCartLine(owner, models.CartEventLineAdded, cartItem)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Ingredient added to cart successfully",
+		"id":      cartItem.ID,
+	})
+}
+
+// @Summary Add a dish to cart
+// @Description Add the ingredients of a dish to user's shopping cart, optionally scaled by servings. Amounts the user's pantry already holds are left out unless ignore_pantry is set
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CartAddDishRequest true "Dish to add"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/add-dish [post]
+func AddDishToCart(c *fiber.Ctx) error {
+	var req models.CartAddDishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	owner := activeCart(c)
+	req.UserID = owner.UserID
+
+	if req.Servings < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid servings",
+		})
+	}
+
+	var dish models.Dish
+	if result := database.DB.First(&dish, req.DishID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found",
+		})
+	}
+
+	var dishIngredients []models.DishIngredient
+	if result := database.DB.Where("dish_id = ?", dish.ID).Find(&dishIngredients); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch dish ingredients",
+		})
+	}
+
+	if len(dishIngredients) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dish has no ingredients",
+		})
+	}
+
+	factor := servingsFactor(dish, req.Servings)
+
+	pantry, err := loadPantryStock(owner.UserID, dishIngredientIDs(dishIngredients), false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch pantry",
+		})
+	}
+
+	tx := database.DB.Begin()
+	lines := []uint{}
+	covered := []models.PantryUsage{}
+	var events []models.CartEvent
+	for _, di := range dishIngredients {
+		needed := utils.ScaleQuantity(di.Quantity, di.Unit, factor)
+		if !req.IgnorePantry {
+			if fromPantry := pantry.take(di.IngredientID, needed, di.Unit); fromPantry > 0 {
+				covered = append(covered, models.PantryUsage{IngredientID: di.IngredientID, Quantity: fromPantry, Unit: di.Unit})
+				needed -= fromPantry
+			}
+		}
+
+		quantity, unit := toCartQuantity(needed, di.Unit)
+		if quantity <= 0 {
+			continue
+		}
+
+		line, created, err := addDishQuantityToCart(tx, owner, dish.ID, di.IngredientID, unit, quantity)
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to add dish to cart",
+			})
+		}
+		lines = append(lines, line.ID)
+
+		eventType := models.CartEventLineUpdated
+		if created {
+			eventType = models.CartEventLineAdded
+		}
+		events = append(events, cartLineEvent(eventType, line))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Transaction failed",
+		})
+	}
+
+	for _, event := range events {
+		publishCartEvent(owner, event)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":           "Dish added to cart successfully",
+		"dish_id":           dish.ID,
+		"cart_ids":          lines,
+		"covered_by_pantry": covered,
+	})
+}
+
+// @Summary Add meal plan to cart
+// @Description Add the ingredients of the meals planned in a range of days to the cart, scaled to each meal's servings and less what the pantry holds. With dry_run the cart is left alone and only the list is returned
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param week query string false "Any day of the week to shop for, instead of from and to"
+// @Param dry_run query bool false "Preview the list without changing the cart"
+// @Param ignore_pantry query bool false "Buy everything, even what the pantry holds"
+// @Success 200 {object} models.CartFromPlanResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/from-plan [post]
+func AddPlanToCart(c *fiber.Ctx) error {
+	from, to, err := parsePlanRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	dryRun := c.QueryBool("dry_run")
+	ignorePantry := c.QueryBool("ignore_pantry")
+	owner := activeCart(c)
+
+	var entries []models.MealPlanEntry
+	if result := database.DB.Where("user_id = ? AND date >= ? AND date <= ?", owner.UserID, from, to).Order("date, id").Find(&entries); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan",
+		})
+	}
+
+	if len(entries) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No meals planned in this range",
+		})
+	}
+
+	needs, err := aggregatePlanIngredients(entries)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch dish ingredients",
+		})
+	}
+
+	ingredientIDs := make([]uint, 0, len(needs))
+	for _, need := range needs {
+		ingredientIDs = append(ingredientIDs, need.ingredientID)
+	}
+
+	pantry, err := loadPantryStock(owner.UserID, ingredientIDs, false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch pantry",
+		})
+	}
+
+	var ingredients []models.Ingredient
+	database.DB.Select("id", "name").Where("id IN ?", ingredientIDs).Find(&ingredients)
+	names := make(map[uint]string, len(ingredients))
+	for _, ingredient := range ingredients {
+		names[ingredient.ID] = ingredient.Name
+	}
+
+	lines := make([]models.CartPlanLine, 0, len(needs))
+	for _, need := range needs {
+		needed := utils.RoundTo(need.quantity, 2)
+		var fromPantry float64
+		if !ignorePantry {
+			fromPantry = pantry.take(need.ingredientID, needed, need.unit)
+		}
+
+		toBuy, unit := toCartQuantity(needed-fromPantry, need.unit)
+		if unit != need.unit {
+			needed, _ = utils.ConvertQuantity(needed, need.unit, unit)
+			fromPantry, _ = utils.ConvertQuantity(fromPantry, need.unit, unit)
+			needed, fromPantry = utils.RoundTo(needed, 2), utils.RoundTo(fromPantry, 2)
+		}
+
+		lines = append(lines, models.CartPlanLine{
+			IngredientID:   need.ingredientID,
+			IngredientName: names[need.ingredientID],
+			Needed:         needed,
+			FromPantry:     fromPantry,
+			ToBuy:          toBuy,
+			Unit:           unit,
+		})
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return strings.ToLower(lines[i].IngredientName) < strings.ToLower(lines[j].IngredientName)
+	})
+
+	if !dryRun {
+		tx := database.DB.Begin()
+		var events []models.CartEvent
+		for i, planLine := range lines {
+			if planLine.ToBuy <= 0 {
+				continue
+			}
+
+			line, _, created, err := addQuantityToCart(tx, owner, planLine.IngredientID, planLine.Unit, planLine.ToBuy)
+			if err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to add meal plan to cart",
+				})
+			}
+			lines[i].CartID = line.ID
+
+			eventType := models.CartEventLineUpdated
+			if created {
+				eventType = models.CartEventLineAdded
+			}
+			events = append(events, cartLineEvent(eventType, line))
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Transaction failed",
+			})
+		}
+
+		for _, event := range events {
+			publishCartEvent(owner, event)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CartFromPlanResponse{
+		From:   from.Format(models.MealPlanDateLayout),
+		To:     to.Format(models.MealPlanDateLayout),
+		DryRun: dryRun,
+		Meals:  len(entries),
+		Lines:  lines,
+	})
+}
+
+// @Summary Remove a dish from cart
+// @Description Subtract the quantities a dish added to user's shopping cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CartRemoveDishRequest true "Dish to remove"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/remove-dish [post]
+func RemoveDishFromCart(c *fiber.Ctx) error {
+	var req models.CartRemoveDishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	owner := activeCart(c)
+	req.UserID = owner.UserID
+
+	lineIDs := owner.lines(database.DB.Model(&models.Cart{})).Select("id")
+
+	var contributions []models.CartDishContribution
+	if result := database.DB.Where("cart_id IN (?) AND dish_id = ?", lineIDs, req.DishID).Find(&contributions); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch cart",
+		})
 	}
 
+	if len(contributions) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found in cart",
+		})
+	}
+
+	tx := database.DB.Begin()
+	var events []models.CartEvent
+	for _, contribution := range contributions {
+		var line models.Cart
+		if result := tx.Where("id = ?", contribution.CartID).Limit(1).Find(&line); result.Error != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update cart",
+			})
+		} else if result.RowsAffected == 0 {
+			continue
+		}
+
+		line.Quantity -= min(contribution.Quantity, line.Quantity)
+		var err error
+		if line.Quantity <= 0 {
+			err = tx.Delete(&line).Error
+			events = append(events, cartLineEvent(models.CartEventLineRemoved, line))
+		} else {
+			err = tx.Save(&line).Error
+			events = append(events, cartLineEvent(models.CartEventLineUpdated, line))
+		}
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update cart",
+			})
+		}
+	}
+
+	contributionIDs := make([]uint, 0, len(contributions))
+	for _, contribution := range contributions {
+		contributionIDs = append(contributionIDs, contribution.ID)
+	}
+	if err := tx.Where("id IN ?", contributionIDs).Delete(&models.CartDishContribution{}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update cart",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Transaction failed",
+		})
+	}
+
+	for _, event := range events {
+		publishCartEvent(owner, event)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Dish removed from cart successfully",
+	})
+}
+
+// addIngredientToCart adds quantity of an ingredient to a cart and
+// writes the response.
+func addIngredientToCart(c *fiber.Ctx, owner cartOwner, req models.CartRequest) error {
+	var existingCartItem models.Cart
+	result := owner.lines(database.DB).Where("ingredient_id = ?", req.IngredientID).First(&existingCartItem)
+
+	if result.RowsAffected > 0 {
+		growCartLine(&existingCartItem, req.Quantity)
+		if result := database.DB.Save(&existingCartItem); result.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update cart",
+			})
+		}
+		publishCartLine(owner, models.CartEventLineUpdated, existingCartItem)
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Cart updated successfully",
+			"id":      existingCartItem.ID,
+		})
+	}
+
+	cartItem := owner.newLine(req.IngredientID, req.Quantity, "")
+
 	if result := database.DB.Create(&cartItem); result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to add to cart",
 		})
 	}
+	publishCartLine(owner, models.CartEventLineAdded, cartItem)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Ingredient added to cart successfully",
 		"id":      cartItem.ID,
 	})
 }
+
+// loadCart builds the response lines of a cart.
+func loadCart(owner cartOwner) ([]models.CartResponse, error) {
+	var cartItems []models.Cart
+	if result := owner.lines(database.DB).Order("id").Find(&cartItems); result.Error != nil {
+		return nil, result.Error
+	}
+
+	var response []models.CartResponse
+	for _, item := range cartItems {
+		var ingredient models.Ingredient
+		database.DB.First(&ingredient, item.IngredientID)
+
+		cartResponse := models.CartResponse{
+			ID:          item.ID,
+			UserID:      item.UserID,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			HouseholdID: item.HouseholdID,
+			AddedBy:     item.AddedBy,
+			Checked:     item.Checked,
+			CheckedAt:   item.CheckedAt,
+		}
+
+		var addedBy models.User
+		if database.DB.Select("id", "user_name").Limit(1).Find(&addedBy, item.AddedBy).RowsAffected > 0 {
+			cartResponse.AddedByName = addedBy.UserName
+		}
+
+		var contributions []models.CartDishContribution
+		database.DB.Where("cart_id = ?", item.ID).Find(&contributions)
+		for _, contribution := range contributions {
+			cartResponse.Sources = append(cartResponse.Sources, models.CartLineSource{
+				DishID:   contribution.DishID,
+				Quantity: contribution.Quantity,
+			})
+		}
+
+		// Set ingredient details
+		cartResponse.Ingredient.ID = ingredient.ID
+		cartResponse.Ingredient.Name = ingredient.Name
+		cartResponse.Ingredient.Section = ingredient.Section
+
+		// Convert image to base64 if exists
+		if len(ingredient.Image) > 0 {
+			cartResponse.Ingredient.Image = base64.StdEncoding.EncodeToString(ingredient.Image)
+		}
+
+		response = append(response, cartResponse)
+	}
+
+	return response, nil
+}
+
+// removeIngredientFromCart deletes an ingredient's line from a cart and writes
+// the response.
+func removeIngredientFromCart(c *fiber.Ctx, owner cartOwner, ingredientID uint) error {
+	var line models.Cart
+	owner.lines(database.DB).Where("ingredient_id = ?", ingredientID).Limit(1).Find(&line)
+
+	lineIDs := owner.lines(database.DB.Model(&models.Cart{})).Select("id").Where("ingredient_id = ?", ingredientID)
+	if err := database.DB.Where("cart_id IN (?)", lineIDs).Delete(&models.CartDishContribution{}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete ingredients from cart",
+		})
+	}
+
+	var result *gorm.DB
+	if result = owner.lines(database.DB).Where("ingredient_id = ?", ingredientID).Delete(&models.Cart{}); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete ingredients from cart",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Not found",
+		})
+	}
+
+	line.Quantity = 0
+	publishCartLine(owner, models.CartEventLineRemoved, line)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Deleted successfully",
+	})
+}
+
+// addQuantityToCart merges quantity, in unit, into a cart line of the
+// ingredient: one whose unit it converts into, or else one without a unit yet.
+// The merged line is counted in the smaller of the two units, or in grams or
+// millilitres when either amount isn't whole in that unit, so nothing is
+// rounded away. Amounts that don't convert into any line get a line of their
+// own. added is the amount added in the line's unit; created reports whether
+// the line is new.
+func addQuantityToCart(tx *gorm.DB, owner cartOwner, ingredientID uint, unit string, quantity int) (line models.Cart, added int, created bool, err error) {
+	var lines []models.Cart
+	if err := owner.lines(tx).Where("ingredient_id = ?", ingredientID).Order("id").Find(&lines).Error; err != nil {
+		return line, 0, false, err
+	}
+
+	for _, existing := range lines {
+		if existing.Unit == "" {
+			continue
+		}
+		if _, ok := utils.ConvertQuantity(float64(quantity), unit, existing.Unit); !ok {
+			continue
+		}
+
+		target, have, more := mergeAmounts(float64(existing.Quantity), existing.Unit, float64(quantity), unit)
+		if err := rescaleCartLine(tx, &existing, target, have); err != nil {
+			return existing, 0, false, err
+		}
+
+		added = ceilQuantity(more)
+		growCartLine(&existing, added)
+		return existing, added, false, tx.Save(&existing).Error
+	}
+
+	// A line added without a unit takes the unit of the first amount that
+	// comes with one.
+	for _, existing := range lines {
+		if existing.Unit == "" {
+			growCartLine(&existing, quantity)
+			existing.Unit = unit
+			return existing, quantity, false, tx.Save(&existing).Error
+		}
+	}
+
+	line = owner.newLine(ingredientID, quantity, unit)
+	return line, quantity, true, tx.Create(&line).Error
+}
+
+// mergeAmounts picks the unit two amounts of an ingredient add up in: the
+// smaller of their units, or grams or millilitres when either amount isn't
+// whole in it. It returns both amounts converted into that unit; the units
+// must convert into each other.
+func mergeAmounts(have float64, haveUnit string, more float64, moreUnit string) (unit string, haveIn, moreIn float64) {
+	unit = haveUnit
+	if scale, _ := utils.ConvertQuantity(1, haveUnit, moreUnit); scale > 1 {
+		unit = moreUnit
+	}
+	if base := utils.BaseUnit(unit); base != unit {
+		haveIn, _ = utils.ConvertQuantity(have, haveUnit, unit)
+		moreIn, _ = utils.ConvertQuantity(more, moreUnit, unit)
+		if !isWholeQuantity(haveIn) || !isWholeQuantity(moreIn) {
+			unit = base
+		}
+	}
+
+	haveIn, _ = utils.ConvertQuantity(have, haveUnit, unit)
+	moreIn, _ = utils.ConvertQuantity(more, moreUnit, unit)
+	return unit, haveIn, moreIn
+}
+
+// rescaleCartLine moves line, and the dish contributions booked against it,
+// into unit, in which the line holds quantity. The line isn't saved.
+func rescaleCartLine(tx *gorm.DB, line *models.Cart, unit string, quantity float64) error {
+	if unit == line.Unit {
+		return nil
+	}
+
+	scale, _ := utils.ConvertQuantity(1, line.Unit, unit)
+	if err := tx.Model(&models.CartDishContribution{}).Where("cart_id = ?", line.ID).
+		Update("quantity", gorm.Expr("CAST(ROUND(quantity * ?) AS INTEGER)", scale)).Error; err != nil {
+		return err
+	}
+	line.Quantity = ceilQuantity(quantity)
+	line.Unit = unit
+	return nil
+}
+
+// addDishQuantityToCart merges quantity into a cart line for the ingredient
+// and books it against the dish it came from. created reports whether the line
+// is new.
+func addDishQuantityToCart(tx *gorm.DB, owner cartOwner, dishID, ingredientID uint, unit string, quantity int) (line models.Cart, created bool, err error) {
+	line, added, created, err := addQuantityToCart(tx, owner, ingredientID, unit, quantity)
+	if err != nil {
+		return line, created, err
+	}
+
+	var contribution models.CartDishContribution
+	result := tx.Where("cart_id = ? AND dish_id = ?", line.ID, dishID).Limit(1).Find(&contribution)
+	if result.Error != nil {
+		return line, created, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		contribution.Quantity += added
+		return line, created, tx.Save(&contribution).Error
+	}
+
+	contribution = models.CartDishContribution{
+		CartID:   line.ID,
+		UserID:   owner.UserID,
+		DishID:   dishID,
+		Quantity: added,
+	}
+	return line, created, tx.Create(&contribution).Error
+}
+
+// planNeed is how much of an ingredient a meal plan calls for, in unit.
+type planNeed struct {
+	ingredientID uint
+	unit         string
+	quantity     float64
+}
+
+// aggregatePlanIngredients adds up the ingredients of the planned meals, each
+// scaled to its meal's servings. Amounts of one ingredient are converted into
+// the smallest of their units; amounts whose units don't convert stay apart.
+func aggregatePlanIngredients(entries []models.MealPlanEntry) ([]planNeed, error) {
+	dishIDs := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		dishIDs = append(dishIDs, entry.DishID)
+	}
+
+	var dishes []models.Dish
+	if err := database.DB.Omit("image", "video_instructions").Where("id IN ?", dishIDs).Find(&dishes).Error; err != nil {
+		return nil, err
+	}
+	dishByID := make(map[uint]models.Dish, len(dishes))
+	for _, dish := range dishes {
+		dishByID[dish.ID] = dish
+	}
+
+	var dishIngredients []models.DishIngredient
+	if err := database.DB.Where("dish_id IN ?", dishIDs).Order("id").Find(&dishIngredients).Error; err != nil {
+		return nil, err
+	}
+	byDish := make(map[uint][]models.DishIngredient)
+	for _, di := range dishIngredients {
+		byDish[di.DishID] = append(byDish[di.DishID], di)
+	}
+
+	var needs []planNeed
+	for _, entry := range entries {
+		dish, found := dishByID[entry.DishID]
+		if !found {
+			continue
+		}
+		factor := servingsFactor(dish, entry.Servings)
+		for _, di := range byDish[dish.ID] {
+			needs = addPlanNeed(needs, di.IngredientID, utils.ScaleQuantity(di.Quantity, di.Unit, factor), di.Unit)
+		}
+	}
+	return needs, nil
+}
+
+func addPlanNeed(needs []planNeed, ingredientID uint, quantity float64, unit string) []planNeed {
+	for i, need := range needs {
+		if need.ingredientID != ingredientID {
+			continue
+		}
+		converted, ok := utils.ConvertQuantity(quantity, unit, need.unit)
+		if !ok {
+			continue
+		}
+
+		if size, _ := utils.ConvertQuantity(1, unit, need.unit); size < 1 {
+			needs[i].quantity, _ = utils.ConvertQuantity(need.quantity, need.unit, unit)
+			needs[i].quantity += quantity
+			needs[i].unit = unit
+		} else {
+			needs[i].quantity += converted
+		}
+		return needs
+	}
+	return append(needs, planNeed{ingredientID: ingredientID, unit: unit, quantity: quantity})
+}
+
+// growCartLine adds quantity to a line. More than was picked up is now
+// needed, so a checked line goes back on the list.
+func growCartLine(line *models.Cart, quantity int) {
+	line.Quantity += quantity
+	if quantity > 0 {
+		line.Checked = false
+		line.CheckedAt = nil
+	}
+}
+
+func dishIngredientIDs(dishIngredients []models.DishIngredient) []uint {
+	ids := make([]uint, 0, len(dishIngredients))
+	for _, di := range dishIngredients {
+		ids = append(ids, di.IngredientID)
+	}
+	return ids
+}
+
+// toCartQuantity rounds a recipe quantity up, cart lines hold whole amounts.
+// A fractional amount of a coarse unit is counted in grams or millilitres
+// first, so 0.5 kg becomes 500 g rather than 1 kg.
+func toCartQuantity(quantity float64, unit string) (int, string) {
+	quantity = utils.RoundTo(quantity, 2)
+	if !isWholeQuantity(quantity) {
+		if base := utils.BaseUnit(unit); base != unit {
+			quantity, _ = utils.ConvertQuantity(quantity, unit, base)
+			quantity, unit = utils.RoundTo(quantity, 2), base
+		}
+	}
+	return ceilQuantity(quantity), unit
+}
+
+// ceilQuantity rounds quantity up to a whole amount, ignoring float noise
+// past two decimals.
+func ceilQuantity(quantity float64) int {
+	return int(math.Ceil(utils.RoundTo(quantity, 2)))
+}
+
+func isWholeQuantity(quantity float64) bool {
+	quantity = utils.RoundTo(quantity, 2)
+	return quantity == math.Trunc(quantity)
+}
+
+// cartOwner identifies the cart a request works on: a user's own cart, or the
+// shared cart of a household when HouseholdID is set. UserID is always the
+// acting user.
+type cartOwner struct {
+	UserID      uint
+	HouseholdID *uint
+}
+
+func personalCart(userID uint) cartOwner {
+	return cartOwner{UserID: userID}
+}
+
+// cartOwnerOf returns the cart a line belongs to.
+func cartOwnerOf(line models.Cart) cartOwner {
+	return cartOwner{UserID: line.UserID, HouseholdID: line.HouseholdID}
+}
+
+// activeCart returns the cart selected by the signed-in user: their active
+// household's cart if they are still a member of it, their own otherwise.
+func activeCart(c *fiber.Ctx) cartOwner {
+	userID := c.Locals("userID").(uint)
+	owner := personalCart(userID)
+
+	var user models.User
+	if database.DB.Select("id", "active_household_id").Limit(1).Find(&user, userID).RowsAffected == 0 || user.ActiveHouseholdID == nil {
+		return owner
+	}
+
+	if isHouseholdMember(*user.ActiveHouseholdID, userID) {
+		owner.HouseholdID = user.ActiveHouseholdID
+	}
+	return owner
+}
+
+// lines scopes a query on carts to the lines of this cart.
+func (o cartOwner) lines(db *gorm.DB) *gorm.DB {
+	if o.HouseholdID != nil {
+		return db.Where("household_id = ?", *o.HouseholdID)
+	}
+	return db.Where("user_id = ? AND household_id IS NULL", o.UserID)
+}
+
+// newLine builds a cart line of this cart added by the acting user.
+func (o cartOwner) newLine(ingredientID uint, quantity int, unit string) models.Cart {
+	return models.Cart{
+		UserID:       o.UserID,
+		HouseholdID:  o.HouseholdID,
+		IngredientID: ingredientID,
+		Quantity:     quantity,
+		Unit:         unit,
+		AddedBy:      o.UserID,
+	}
+}
@@ -0,0 +1,512 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get collections
+// @Description Get the current user's favorite collections, default first
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.CollectionResponse
+// @Failure 500 {object} map[string]string
+// @Router /collections [get]
+func GetCollections(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var collections []models.FavoriteCollection
+	if result := database.DB.Where("user_id = ?", userID).Order("is_default DESC, name, id").Find(&collections); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch collections",
+		})
+	}
+
+	response := []models.CollectionResponse{}
+	for _, collection := range collections {
+		response = append(response, convertCollectionToResponse(collection))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Get collection
+// @Description Get a collection with its dishes in their manual order
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Success 200 {object} models.CollectionWithDishes
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections/{id} [get]
+func GetCollection(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(loadCollectionWithDishes(c, collection))
+}
+
+// @Summary Create collection
+// @Description Create a named favorites collection for the current user
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CollectionRequest true "Collection name"
+// @Success 201 {object} models.CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections [post]
+func CreateCollection(c *fiber.Ctx) error {
+	var req models.CollectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	if collectionNameTaken(userID, name, 0) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Collection already exists",
+		})
+	}
+
+	collection := models.FavoriteCollection{
+		UserID: userID,
+		Name:   name,
+	}
+
+	if result := database.DB.Create(&collection); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create collection",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(convertCollectionToResponse(collection))
+}
+
+// @Summary Rename collection
+// @Description Rename one of the current user's collections
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Param request body models.CollectionRequest true "New name"
+// @Success 200 {object} models.CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id} [put]
+func RenameCollection(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.CollectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	if collectionNameTaken(collection.UserID, name, collection.ID) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Collection already exists",
+		})
+	}
+
+	collection.Name = name
+	if result := database.DB.Save(&collection); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to rename collection",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(convertCollectionToResponse(collection))
+}
+
+// @Summary Delete collection
+// @Description Delete a collection, the dishes saved in it and its share links; the default collection cannot be deleted
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id} [delete]
+func DeleteCollection(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	if collection.IsDefault {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "The default collection cannot be deleted",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.FavoriteDish{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("resource_type = ? AND resource_id = ?", models.ShareResourceCollection, collection.ID).Delete(&models.ShareLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&collection).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete collection",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Collection deleted successfully",
+	})
+}
+
+// @Summary Add dish to collection
+// @Description Add a dish with an optional personal note to the end of a collection
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Param request body models.CollectionDishRequest true "Dish and note"
+// @Success 201 {object} models.FavoriteDish
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/dishes [post]
+func AddCollectionDish(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.CollectionDishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	return addDishToCollection(c, collection, req)
+}
+
+// @Summary Update collection dish note
+// @Description Replace the personal note on a dish in a collection
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Param dish_id path int true "Dish ID"
+// @Param request body models.CollectionNoteRequest true "Note"
+// @Success 200 {object} models.FavoriteDish
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/dishes/{dish_id} [put]
+func UpdateCollectionDish(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.CollectionNoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var favorite models.FavoriteDish
+	if result := database.DB.Where("collection_id = ? AND dish_id = ?", collection.ID, c.Params("dish_id")).First(&favorite); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found in collection",
+		})
+	}
+
+	favorite.Note = strings.TrimSpace(req.Note)
+	if result := database.DB.Save(&favorite); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update note",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(favorite)
+}
+
+// @Summary Remove dish from collection
+// @Description Remove a dish from a collection
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Param dish_id path int true "Dish ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/dishes/{dish_id} [delete]
+func RemoveCollectionDish(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	return removeDishFromCollection(c, collection, c.Params("dish_id"))
+}
+
+// @Summary Reorder collection
+// @Description Set the manual order of a collection; dish_ids must list every dish in it exactly once
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Collection ID"
+// @Param request body models.CollectionOrderRequest true "Dish IDs in order"
+// @Success 200 {object} models.CollectionWithDishes
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/order [put]
+func ReorderCollection(c *fiber.Ctx) error {
+	collection, ok, err := findCollectionFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.CollectionOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var favorites []models.FavoriteDish
+	database.DB.Where("collection_id = ?", collection.ID).Find(&favorites)
+
+	byDish := make(map[uint]models.FavoriteDish, len(favorites))
+	for _, favorite := range favorites {
+		byDish[favorite.DishID] = favorite
+	}
+
+	if len(req.DishIDs) != len(favorites) || len(uniqueIDs(req.DishIDs)) != len(req.DishIDs) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "dish_ids must list every dish in the collection exactly once",
+		})
+	}
+	for _, dishID := range req.DishIDs {
+		if _, ok := byDish[dishID]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "dish_ids must list every dish in the collection exactly once",
+			})
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, dishID := range req.DishIDs {
+			if err := tx.Model(&models.FavoriteDish{}).Where("id = ?", byDish[dishID].ID).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reorder collection",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(loadCollectionWithDishes(c, collection))
+}
+
+// findCollectionFromParam loads the :id collection of the current user,
+// writing the 400/404 response itself when it cannot.
+func findCollectionFromParam(c *fiber.Ctx) (collection models.FavoriteCollection, ok bool, err error) {
+	collectionID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return collection, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid collection ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if result := database.DB.Where("id = ? AND user_id = ?", collectionID, userID).First(&collection); result.Error != nil {
+		return collection, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Collection not found",
+		})
+	}
+
+	return collection, true, nil
+}
+
+// addDishToCollection appends a dish to a collection and writes the response.
+func addDishToCollection(c *fiber.Ctx, collection models.FavoriteCollection, req models.CollectionDishRequest) error {
+	var dish models.Dish
+	if result := database.DB.First(&dish, req.DishID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found",
+		})
+	}
+
+	var existing models.FavoriteDish
+	result := database.DB.Where("collection_id = ? AND dish_id = ?", collection.ID, dish.ID).First(&existing)
+	if result.RowsAffected > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Dish already in collection",
+		})
+	}
+
+	favorite := models.FavoriteDish{
+		UserID:       collection.UserID,
+		DishID:       dish.ID,
+		CollectionID: collection.ID,
+		Note:         strings.TrimSpace(req.Note),
+		Position:     nextCollectionPosition(collection.ID),
+	}
+
+	if result := database.DB.Create(&favorite); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add dish to collection",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(favorite)
+}
+
+// removeDishFromCollection removes a dish from a collection and writes the
+// response.
+func removeDishFromCollection(c *fiber.Ctx, collection models.FavoriteCollection, dishID string) error {
+	result := database.DB.Where("collection_id = ? AND dish_id = ?", collection.ID, dishID).Delete(&models.FavoriteDish{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove dish from collection",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found in collection",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Dish removed from collection successfully",
+	})
+}
+
+// defaultCollection returns the user's default collection, creating it on
+// first use.
+func defaultCollection(db *gorm.DB, userID uint) (models.FavoriteCollection, error) {
+	var collection models.FavoriteCollection
+	result := db.Where("user_id = ? AND is_default = ?", userID, true).Limit(1).Find(&collection)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return collection, result.Error
+	}
+
+	collection = models.FavoriteCollection{
+		UserID:    userID,
+		Name:      models.DefaultCollectionName,
+		IsDefault: true,
+	}
+	return collection, db.Create(&collection).Error
+}
+
+func nextCollectionPosition(collectionID uint) int {
+	var position int
+	database.DB.Model(&models.FavoriteDish{}).
+		Where("collection_id = ?", collectionID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&position)
+	return position + 1
+}
+
+func collectionNameTaken(userID uint, name string, excludeID uint) bool {
+	var count int64
+	database.DB.Model(&models.FavoriteCollection{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, excludeID).
+		Count(&count)
+	return count > 0
+}
+
+func convertCollectionToResponse(collection models.FavoriteCollection) models.CollectionResponse {
+	var count int64
+	database.DB.Model(&models.FavoriteDish{}).Where("collection_id = ?", collection.ID).Count(&count)
+
+	return models.CollectionResponse{
+		ID:        collection.ID,
+		Name:      collection.Name,
+		IsDefault: collection.IsDefault,
+		DishCount: count,
+		CreatedAt: collection.CreatedAt,
+	}
+}
+
+func loadCollectionWithDishes(c *fiber.Ctx, collection models.FavoriteCollection) models.CollectionWithDishes {
+	var favorites []models.FavoriteDish
+	database.DB.Where("collection_id = ?", collection.ID).Order("position, id").Find(&favorites)
+
+	var dishesWithIngredients []models.DishWithIngredients
+	for _, favorite := range favorites {
+		var dish models.Dish
+		database.DB.First(&dish, favorite.DishID)
+
+		dishesWithIngredients = append(dishesWithIngredients, loadDishWithIngredients(dish))
+	}
+	dishesWithIngredients = applyDietaryProfile(c, dishesWithIngredients, profileModeFlag)
+	dishesWithIngredients = applyDishRatings(dishesWithIngredients)
+	dishesWithIngredients = applyDishCosts(dishesWithIngredients, models.DefaultCurrency)
+
+	response := models.CollectionWithDishes{
+		CollectionResponse: convertCollectionToResponse(collection),
+		Dishes:             []models.CollectionDishResponse{},
+	}
+	for i, dish := range dishesWithIngredients {
+		response.Dishes = append(response.Dishes, models.CollectionDishResponse{
+			DishWithIngredients: dish,
+			Note:                favorites[i].Note,
+			Position:            favorites[i].Position,
+		})
+	}
+	return response
+}
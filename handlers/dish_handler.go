@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"encoding/base64"
+	"errors"
 	"foodapp/database"
 	"foodapp/models"
+	"foodapp/utils"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // @Summary Get all dishes
@@ -15,12 +19,64 @@ import (
 // @Tags dishes
 // @Accept json
 // @Produce json
+// @Param servings query int false "Scale ingredients and nutrition to this many servings"
+// @Param profile query string false "Dietary profile handling for signed-in callers: flag (default), hide or off"
+// @Param tags query string false "Comma-separated tag names to filter by"
+// @Param match query string false "Tag match mode: any (default) or all"
+// @Param facets query bool false "Wrap the result with tag facet counts"
+// @Param sort query string false "Sort order: rating (best rated first) or cost (cheapest per serving first)"
+// @Param currency query string false "Currency for cost estimates (default EUR)"
+// @Param max_cost query number false "Only fully priced dishes whose estimated cost per serving is at most this"
 // @Success 200 {array} models.DishWithIngredients
+// @Success 200 {object} models.DishSearchResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /dishes [get]
 func GetAllDishes(c *fiber.Ctx) error {
+	servings, err := parseServingsQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid servings",
+		})
+	}
+
+	profileMode, err := parseProfileMode(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile mode",
+		})
+	}
+
+	tags, matchAll, err := parseTagFilter(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tag filter",
+		})
+	}
+
+	currency, err := parseCurrencyQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid currency",
+		})
+	}
+
+	maxCost, err := parseMaxCostQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid max_cost",
+		})
+	}
+
+	query, err := applyDishSort(applyTagFilter(database.DB, tags, matchAll), c.Query("sort"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid sort",
+		})
+	}
+
 	var dishes []models.Dish
-	if result := database.DB.Find(&dishes); result.Error != nil {
+	if result := query.Find(&dishes); result.Error != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get dishes",
 		})
@@ -45,6 +101,7 @@ func GetAllDishes(c *fiber.Ctx) error {
 				Fats:            dishResponse.Fats,
 				Carbs:           dishResponse.Carbs,
 				Proteins:        dishResponse.Proteins,
+				Servings:        dishResponse.Servings,
 				Category:        dishResponse.Category,
 				Image:           imageBytes,
 				CreatedAt:       dishResponse.CreatedAt,
@@ -69,10 +126,27 @@ func GetAllDishes(c *fiber.Ctx) error {
 				Name:     ingredient.Name,
 				Image:    imageBase64,
 				Quantity: di.Quantity,
+				Unit:     di.Unit,
 			})
 		}
 
-		dishesWithIngredients = append(dishesWithIngredients, dishWithIngredients)
+		dishWithIngredients.Tags = loadDishTags(dish.ID)
+		dishesWithIngredients = append(dishesWithIngredients, scaleDishWithIngredients(dishWithIngredients, servings))
+	}
+
+	dishesWithIngredients = applyDietaryProfile(c, dishesWithIngredients, profileMode)
+	dishesWithIngredients = applyDishRatings(dishesWithIngredients)
+	dishesWithIngredients = applyDishCosts(dishesWithIngredients, currency)
+
+	if maxCost > 0 {
+		dishesWithIngredients = filterDishesByCost(dishesWithIngredients, maxCost)
+	}
+	if c.Query("sort") == "cost" {
+		sortDishesByCost(dishesWithIngredients)
+	}
+
+	if c.QueryBool("facets") {
+		return c.Status(http.StatusOK).JSON(withTagFacets(dishesWithIngredients))
 	}
 
 	return c.Status(http.StatusOK).JSON(dishesWithIngredients)
@@ -84,6 +158,9 @@ func GetAllDishes(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param q query string true "Category name"
+// @Param servings query int false "Scale ingredients and nutrition to this many servings"
+// @Param profile query string false "Dietary profile handling for signed-in callers: flag (default), hide or off"
+// @Param currency query string false "Currency for cost estimates (default EUR)"
 // @Success 200 {array} models.DishWithIngredients
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -97,6 +174,27 @@ func GetDishesByCategory(c *fiber.Ctx) error {
 		})
 	}
 
+	servings, err := parseServingsQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid servings",
+		})
+	}
+
+	profileMode, err := parseProfileMode(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile mode",
+		})
+	}
+
+	currency, err := parseCurrencyQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid currency",
+		})
+	}
+
 	var dishes []models.Dish
 	if result := database.DB.Where("category = ?", category).Find(&dishes); result.Error != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
@@ -121,6 +219,7 @@ func GetDishesByCategory(c *fiber.Ctx) error {
 				Fats:            dishResponse.Fats,
 				Carbs:           dishResponse.Carbs,
 				Proteins:        dishResponse.Proteins,
+				Servings:        dishResponse.Servings,
 				Category:        dishResponse.Category,
 				CreatedAt:       dishResponse.CreatedAt,
 				Instruction:     dishResponse.Instruction,
@@ -144,12 +243,18 @@ func GetDishesByCategory(c *fiber.Ctx) error {
 				Name:     ingredient.Name,
 				Image:    imageBase64,
 				Quantity: di.Quantity,
+				Unit:     di.Unit,
 			})
 		}
 
-		dishesWithIngredients = append(dishesWithIngredients, dishWithIngredients)
+		dishWithIngredients.Tags = loadDishTags(dish.ID)
+		dishesWithIngredients = append(dishesWithIngredients, scaleDishWithIngredients(dishWithIngredients, servings))
 	}
 
+	dishesWithIngredients = applyDietaryProfile(c, dishesWithIngredients, profileMode)
+	dishesWithIngredients = applyDishRatings(dishesWithIngredients)
+	dishesWithIngredients = applyDishCosts(dishesWithIngredients, currency)
+
 	return c.Status(fiber.StatusOK).JSON(dishesWithIngredients)
 }
 
@@ -159,7 +264,16 @@ func GetDishesByCategory(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param q query string true "Search query"
+// @Param servings query int false "Scale ingredients and nutrition to this many servings"
+// @Param profile query string false "Dietary profile handling for signed-in callers: flag (default), hide or off"
+// @Param tags query string false "Comma-separated tag names to filter by"
+// @Param match query string false "Tag match mode: any (default) or all"
+// @Param facets query bool false "Wrap the result with tag facet counts"
+// @Param sort query string false "Sort order: rating (best rated first) or cost (cheapest per serving first)"
+// @Param currency query string false "Currency for cost estimates (default EUR)"
+// @Param max_cost query number false "Only fully priced dishes whose estimated cost per serving is at most this"
 // @Success 200 {array} models.DishWithIngredients
+// @Success 200 {object} models.DishSearchResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /dishes/search [get]
@@ -171,8 +285,50 @@ func SearchDishesByName(c *fiber.Ctx) error {
 		})
 	}
 
+	servings, err := parseServingsQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid servings",
+		})
+	}
+
+	profileMode, err := parseProfileMode(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile mode",
+		})
+	}
+
+	tags, matchAll, err := parseTagFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tag filter",
+		})
+	}
+
+	currency, err := parseCurrencyQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid currency",
+		})
+	}
+
+	maxCost, err := parseMaxCostQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid max_cost",
+		})
+	}
+
+	query, err := applyDishSort(applyTagFilter(database.DB.Where("dishes.name LIKE ?", "%"+searchQuery+"%"), tags, matchAll), c.Query("sort"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid sort",
+		})
+	}
+
 	var dishes []models.Dish
-	if result := database.DB.Where("name LIKE ?", "%"+searchQuery+"%").Find(&dishes); result.Error != nil {
+	if result := query.Find(&dishes); result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to search dishes",
 		})
@@ -195,6 +351,7 @@ func SearchDishesByName(c *fiber.Ctx) error {
 				Fats:            dishResponse.Fats,
 				Carbs:           dishResponse.Carbs,
 				Proteins:        dishResponse.Proteins,
+				Servings:        dishResponse.Servings,
 				Category:        dishResponse.Category,
 				CreatedAt:       dishResponse.CreatedAt,
 				Instruction:     dishResponse.Instruction,
@@ -218,10 +375,27 @@ func SearchDishesByName(c *fiber.Ctx) error {
 				Name:     ingredient.Name,
 				Image:    imageBase64,
 				Quantity: di.Quantity,
+				Unit:     di.Unit,
 			})
 		}
 
-		dishesWithIngredients = append(dishesWithIngredients, dishWithIngredients)
+		dishWithIngredients.Tags = loadDishTags(dish.ID)
+		dishesWithIngredients = append(dishesWithIngredients, scaleDishWithIngredients(dishWithIngredients, servings))
+	}
+
+	dishesWithIngredients = applyDietaryProfile(c, dishesWithIngredients, profileMode)
+	dishesWithIngredients = applyDishRatings(dishesWithIngredients)
+	dishesWithIngredients = applyDishCosts(dishesWithIngredients, currency)
+
+	if maxCost > 0 {
+		dishesWithIngredients = filterDishesByCost(dishesWithIngredients, maxCost)
+	}
+	if c.Query("sort") == "cost" {
+		sortDishesByCost(dishesWithIngredients)
+	}
+
+	if c.QueryBool("facets") {
+		return c.Status(fiber.StatusOK).JSON(withTagFacets(dishesWithIngredients))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dishesWithIngredients)
@@ -236,6 +410,7 @@ func convertDishToResponse(dish models.Dish) models.DishResponse {
 		Fats:            dish.Fats,
 		Carbs:           dish.Carbs,
 		Proteins:        dish.Proteins,
+		Servings:        dish.Servings,
 		Category:        dish.Category,
 		CreatedAt:       dish.CreatedAt,
 		Instruction:     dish.Instruction,
@@ -252,8 +427,102 @@ func convertDishToResponse(dish models.Dish) models.DishResponse {
 	return response
 }
 
+// applyDishSort orders a dish query by the ?sort parameter. Without one dishes
+// keep their natural order.
+func applyDishSort(query *gorm.DB, sort string) (*gorm.DB, error) {
+	switch sort {
+	case "":
+		return query, nil
+	case "cost":
+		// Costs need unit conversion, the handler sorts after pricing dishes.
+		return query, nil
+	case "rating":
+		return query.Select("dishes.*").
+			Joins("LEFT JOIN (?) AS ratings ON ratings.dish_id = dishes.id", visibleRatings()).
+			Order("COALESCE(ratings.average, 0) DESC, COALESCE(ratings.count, 0) DESC, dishes.id"), nil
+	default:
+		return nil, errors.New("unknown sort")
+	}
+}
+
+func withTagFacets(dishes []models.DishWithIngredients) models.DishSearchResponse {
+	dishIDs := make([]uint, 0, len(dishes))
+	for _, dish := range dishes {
+		dishIDs = append(dishIDs, dish.Dish.ID)
+	}
+
+	if dishes == nil {
+		dishes = []models.DishWithIngredients{}
+	}
+
+	return models.DishSearchResponse{
+		Dishes: dishes,
+		Facets: tagFacets(dishIDs),
+	}
+}
+
+// parseServingsQuery reads the optional ?servings=N parameter; 0 means the
+// dish is returned for its own number of servings.
+func parseServingsQuery(c *fiber.Ctx) (int, error) {
+	raw := c.Query("servings")
+	if raw == "" {
+		return 0, nil
+	}
+
+	servings, err := strconv.Atoi(raw)
+	if err != nil || servings <= 0 {
+		return 0, errors.New("servings must be a positive integer")
+	}
+	return servings, nil
+}
+
+func dishBaseServings(dish models.Dish) int {
+	if dish.Servings <= 0 {
+		return 1
+	}
+	return dish.Servings
+}
+
+// servingsFactor is the multiplier that turns the dish's own ingredient
+// quantities into quantities for the requested number of servings.
+func servingsFactor(dish models.Dish, servings int) float64 {
+	if servings <= 0 {
+		return 1
+	}
+	return float64(servings) / float64(dishBaseServings(dish))
+}
+
+func scaleDishWithIngredients(dwi models.DishWithIngredients, servings int) models.DishWithIngredients {
+	if servings <= 0 {
+		servings = dishBaseServings(dwi.Dish)
+	}
+	factor := servingsFactor(dwi.Dish, servings)
+
+	for i, ingredient := range dwi.Ingredients {
+		dwi.Ingredients[i].Quantity = utils.ScaleQuantity(ingredient.Quantity, ingredient.Unit, factor)
+	}
+
+	perServing := models.Nutrition{
+		Calories: float64(dwi.Dish.Calories),
+		Fats:     float64(dwi.Dish.Fats),
+		Carbs:    float64(dwi.Dish.Carbs),
+		Proteins: float64(dwi.Dish.Proteins),
+	}
+	total := models.Nutrition{
+		Calories: utils.RoundTo(perServing.Calories*float64(servings), 1),
+		Fats:     utils.RoundTo(perServing.Fats*float64(servings), 1),
+		Carbs:    utils.RoundTo(perServing.Carbs*float64(servings), 1),
+		Proteins: utils.RoundTo(perServing.Proteins*float64(servings), 1),
+	}
+
+	dwi.Servings = servings
+	dwi.PerServing = &perServing
+	dwi.Total = &total
+	return dwi
+}
+
 // @Summary Create new dish
-// @Description Create a new dish with ingredients
+// @Description Create a new dish with ingredients. Each line of the instruction becomes a step
 // @Tags dishes
 // @Accept json
 // @Produce json
@@ -272,6 +541,10 @@ func CreateDish(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.Servings <= 0 {
+		req.Servings = 1
+	}
+
 	dish := models.Dish{
 		Name:              req.Name,
 		PreparationTime:   req.PreparationTime,
@@ -279,6 +552,7 @@ func CreateDish(c *fiber.Ctx) error {
 		Fats:              req.Fats,
 		Carbs:             req.Carbs,
 		Proteins:          req.Proteins,
+		Servings:          req.Servings,
 		Category:          req.Category,
 		Image:             req.Image,
 		CreatedAt:         time.Now(),
@@ -294,11 +568,19 @@ func CreateDish(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := database.CreateInstructionSteps(tx, dish.ID, dish.Instruction); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add dish steps",
+		})
+	}
+
 	for _, ingredient := range req.Ingredients {
 		dishIngredient := models.DishIngredient{
 			DishID:       dish.ID,
 			IngredientID: ingredient.IngredientID,
 			Quantity:     ingredient.Quantity,
+			Unit:         ingredient.Unit,
 		}
 
 		if err := tx.Create(&dishIngredient).Error; err != nil {
@@ -309,6 +591,20 @@ func CreateDish(c *fiber.Ctx) error {
 		}
 	}
 
+	if len(req.TagIDs) > 0 {
+		if err := addTagsToDish(tx, dish.ID, req.TagIDs); err != nil {
+			tx.Rollback()
+			if errors.Is(err, errUnknownTag) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Unknown tag",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to add dish tags",
+			})
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Transaction failed",
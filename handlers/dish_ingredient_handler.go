@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"encoding/base64"
 	"foodapp/database"
 	"foodapp/models"
+	"foodapp/utils"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,6 +15,8 @@ import (
 // @Accept json
 // @Produce json
 // @Param dish_id path int true "Dish ID"
+// @Param servings query int false "Scale quantities to this many servings"
+// @Param substitute_for query string false "Swap ingredients for substitutes declared for this dietary reason"
 // @Success 200 {array} models.DishIngredientResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -29,12 +31,20 @@ func GetDishIngredients(c *fiber.Ctx) error {
 		})
 	}
 
+	servings, err := parseServingsQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid servings",
+		})
+	}
+
 	var dish models.Dish
 	if result := database.DB.First(&dish, dishID); result.Error != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Dish not found",
 		})
 	}
+	factor := servingsFactor(dish, servings)
 
 	var dishIngredients []models.DishIngredient
 	if result := database.DB.Where("dish_id = ?", dishID).Find(&dishIngredients); result.Error != nil {
@@ -43,25 +53,36 @@ func GetDishIngredients(c *fiber.Ctx) error {
 		})
 	}
 
+	var ingredientIDs []uint
+	for _, di := range dishIngredients {
+		ingredientIDs = append(ingredientIDs, di.IngredientID)
+	}
+	substitutes := substitutesFor(ingredientIDs, normalizeDietaryReason(c.Query("substitute_for")))
+
 	var response []models.DishIngredientResponse
 	for _, di := range dishIngredients {
 		var ingredient models.Ingredient
 		database.DB.First(&ingredient, di.IngredientID)
 
-		var imageBase64 string
-		if len(ingredient.Image) > 0 {
-			imageBase64 = base64.StdEncoding.EncodeToString(ingredient.Image)
+		item := models.DishIngredientResponse{
+			DishID:     di.DishID,
+			Ingredient: convertIngredientToResponse(ingredient),
+			Quantity:   utils.ScaleQuantity(di.Quantity, di.Unit, factor),
+			Unit:       di.Unit,
 		}
 
-		response = append(response, models.DishIngredientResponse{
-			DishID: di.DishID,
-			Ingredient: models.IngredientResponse{
-				ID:    ingredient.ID,
-				Name:  ingredient.Name,
-				Image: imageBase64,
-			},
-			Quantity: di.Quantity,
-		})
+		if substitution, ok := substitutes[di.IngredientID]; ok {
+			var substitute models.Ingredient
+			if result := database.DB.First(&substitute, substitution.SubstituteID); result.Error == nil {
+				original := item.Ingredient
+				item.Ingredient = convertIngredientToResponse(substitute)
+				item.Quantity = utils.ScaleQuantity(di.Quantity, di.Unit, factor*substitution.Ratio)
+				item.SubstitutedFor = &original
+				item.SubstitutionNote = substitution.Notes
+			}
+		}
+
+		response = append(response, item)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -90,6 +111,7 @@ func AddIngredientToDishes(c *fiber.Ctx) error {
 		DishID:       req.DishID,
 		IngredientID: req.IngredientID,
 		Quantity:     req.Quantity,
+		Unit:         req.Unit,
 	}
 
 	if result := database.DB.Create(&dishIngredient); result.Error != nil {
@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"foodapp/database"
+	"foodapp/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get dish steps
+// @Description Get the ordered cooking steps of a dish
+// @Tags dish-steps
+// @Accept json
+// @Produce json
+// @Param id path int true "Dish ID"
+// @Success 200 {array} models.DishStepResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/steps [get]
+func GetDishSteps(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var steps []models.DishStep
+	if result := database.DB.Where("dish_id = ?", dish.ID).Order("position").Find(&steps); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch dish steps",
+		})
+	}
+
+	response := []models.DishStepResponse{}
+	for _, step := range steps {
+		response = append(response, convertDishStepToResponse(step))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Add dish step
+// @Description Add a step to a dish; without a position the step is appended
+// @Tags dish-steps
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Param step body models.DishStepRequest true "Step details"
+// @Success 201 {object} models.DishStepResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/steps [post]
+func AddDishStep(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.DishStepRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if msg := validateDishStepRequest(dish.ID, req); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	var count int64
+	database.DB.Model(&models.DishStep{}).Where("dish_id = ?", dish.ID).Count(&count)
+
+	position := req.Position
+	if position <= 0 || position > int(count)+1 {
+		position = int(count) + 1
+	}
+
+	step := models.DishStep{
+		DishID:          dish.ID,
+		Position:        position,
+		Text:            req.Text,
+		DurationSeconds: req.DurationSeconds,
+		Image:           req.Image,
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Model(&models.DishStep{}).
+		Where("dish_id = ? AND position >= ?", dish.ID, position).
+		Update("position", gorm.Expr("position + 1")).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add dish step",
+		})
+	}
+
+	if err := tx.Create(&step).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add dish step",
+		})
+	}
+
+	if err := replaceStepIngredients(tx, step.ID, req.IngredientIDs); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add dish step",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Transaction failed",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(convertDishStepToResponse(step))
+}
+
+// @Summary Update dish step
+// @Description Update a step's text, timer, image, ingredients or position
+// @Tags dish-steps
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Param step_id path int true "Step ID"
+// @Param step body models.DishStepRequest true "Step details"
+// @Success 200 {object} models.DishStepResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/steps/{step_id} [put]
+func UpdateDishStep(c *fiber.Ctx) error {
+	step, ok, err := findDishStepFromParams(c)
+	if !ok {
+		return err
+	}
+
+	var req models.DishStepRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if msg := validateDishStepRequest(step.DishID, req); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	var count int64
+	database.DB.Model(&models.DishStep{}).Where("dish_id = ?", step.DishID).Count(&count)
+
+	position := req.Position
+	if position <= 0 {
+		position = step.Position
+	}
+	if position > int(count) {
+		position = int(count)
+	}
+
+	tx := database.DB.Begin()
+	if position != step.Position {
+		var shift *gorm.DB
+		if position < step.Position {
+			shift = tx.Model(&models.DishStep{}).
+				Where("dish_id = ? AND position >= ? AND position < ?", step.DishID, position, step.Position).
+				Update("position", gorm.Expr("position + 1"))
+		} else {
+			shift = tx.Model(&models.DishStep{}).
+				Where("dish_id = ? AND position > ? AND position <= ?", step.DishID, step.Position, position).
+				Update("position", gorm.Expr("position - 1"))
+		}
+		if shift.Error != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update dish step",
+			})
+		}
+	}
+
+	step.Position = position
+	step.Text = req.Text
+	step.DurationSeconds = req.DurationSeconds
+	if req.Image != nil {
+		step.Image = req.Image
+	}
+
+	if err := tx.Save(&step).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update dish step",
+		})
+	}
+
+	if err := replaceStepIngredients(tx, step.ID, req.IngredientIDs); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update dish step",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Transaction failed",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(convertDishStepToResponse(step))
+}
+
+// @Summary Delete dish step
+// @Description Delete a step and close the gap in the step order
+// @Tags dish-steps
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Param step_id path int true "Step ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/steps/{step_id} [delete]
+func DeleteDishStep(c *fiber.Ctx) error {
+	step, ok, err := findDishStepFromParams(c)
+	if !ok {
+		return err
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Where("step_id = ?", step.ID).Delete(&models.DishStepIngredient{}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete dish step",
+		})
+	}
+
+	if err := tx.Delete(&step).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete dish step",
+		})
+	}
+
+	if err := tx.Model(&models.DishStep{}).
+		Where("dish_id = ? AND position > ?", step.DishID, step.Position).
+		Update("position", gorm.Expr("position - 1")).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete dish step",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Transaction failed",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Dish step deleted successfully",
+	})
+}
+
+// findDishFromParam loads the dish named by the :id route parameter. When ok
+// is false the error response has already been written and err must be
+// returned by the handler.
+func findDishFromParam(c *fiber.Ctx) (dish models.Dish, ok bool, err error) {
+	dishID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return dish, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid dish ID",
+		})
+	}
+
+	if result := database.DB.First(&dish, dishID); result.Error != nil {
+		return dish, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found",
+		})
+	}
+
+	return dish, true, nil
+}
+
+func findDishStepFromParams(c *fiber.Ctx) (step models.DishStep, ok bool, err error) {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return step, false, err
+	}
+
+	stepID, convErr := strconv.Atoi(c.Params("step_id"))
+	if convErr != nil {
+		return step, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid step ID",
+		})
+	}
+
+	if result := database.DB.Where("id = ? AND dish_id = ?", stepID, dish.ID).First(&step); result.Error != nil {
+		return step, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish step not found",
+		})
+	}
+
+	return step, true, nil
+}
+
+// validateDishStepRequest returns an error message for invalid input or an
+// empty string. Referenced ingredients must be part of the dish.
+func validateDishStepRequest(dishID uint, req models.DishStepRequest) string {
+	if req.Text == "" {
+		return "Step text is required"
+	}
+
+	if req.DurationSeconds < 0 {
+		return "Duration cannot be negative"
+	}
+
+	if len(req.IngredientIDs) > 0 {
+		var count int64
+		database.DB.Model(&models.DishIngredient{}).
+			Where("dish_id = ? AND ingredient_id IN ?", dishID, req.IngredientIDs).
+			Distinct("ingredient_id").
+			Count(&count)
+		if int(count) != len(uniqueIDs(req.IngredientIDs)) {
+			return "Step references ingredients that are not part of the dish"
+		}
+	}
+
+	return ""
+}
+
+func replaceStepIngredients(tx *gorm.DB, stepID uint, ingredientIDs []uint) error {
+	if err := tx.Where("step_id = ?", stepID).Delete(&models.DishStepIngredient{}).Error; err != nil {
+		return err
+	}
+
+	for _, ingredientID := range uniqueIDs(ingredientIDs) {
+		link := models.DishStepIngredient{
+			StepID:       stepID,
+			IngredientID: ingredientID,
+		}
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertDishStepToResponse(step models.DishStep) models.DishStepResponse {
+	response := models.DishStepResponse{
+		ID:              step.ID,
+		DishID:          step.DishID,
+		Position:        step.Position,
+		Text:            step.Text,
+		DurationSeconds: step.DurationSeconds,
+		IngredientIDs:   []uint{},
+	}
+
+	if len(step.Image) > 0 {
+		response.Image = base64.StdEncoding.EncodeToString(step.Image)
+	}
+
+	var links []models.DishStepIngredient
+	database.DB.Where("step_id = ?", step.ID).Order("id").Find(&links)
+	for _, link := range links {
+		response.IngredientIDs = append(response.IngredientIDs, link.IngredientID)
+	}
+
+	return response
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var unique []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -1,11 +1,10 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"foodapp/database"
 	"foodapp/models"
 
-	"encoding/base64"
-
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -29,8 +28,15 @@ func AddFavoriteDish(c *fiber.Ctx) error {
 		})
 	}
 
+	collection, err := defaultCollection(database.DB, req.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add dish to favorites",
+		})
+	}
+
 	var existingFavorite models.FavoriteDish
-	result := database.DB.Where("user_id = ? AND dish_id = ?", req.UserID, req.DishID).First(&existingFavorite)
+	result := database.DB.Where("collection_id = ? AND dish_id = ?", collection.ID, req.DishID).First(&existingFavorite)
 	if result.RowsAffected > 0 {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"error": "Dish already in favorites",
@@ -38,8 +44,10 @@ func AddFavoriteDish(c *fiber.Ctx) error {
 	}
 
 	favorite := models.FavoriteDish{
-		UserID: req.UserID,
-		DishID: req.DishID,
+		UserID:       req.UserID,
+		DishID:       req.DishID,
+		CollectionID: collection.ID,
+		Position:     nextCollectionPosition(collection.ID),
 	}
 
 	if result := database.DB.Create(&favorite); result.Error != nil {
@@ -73,7 +81,10 @@ func DeleteFavoriteDish(c *fiber.Ctx) error {
 		})
 	}
 
-	result := database.DB.Where("user_id = ? AND dish_id = ?", req.UserID, req.DishID).Delete(&models.FavoriteDish{})
+	result := database.DB.
+		Where("user_id = ? AND dish_id = ?", req.UserID, req.DishID).
+		Where("collection_id IN (?)", database.DB.Model(&models.FavoriteCollection{}).Select("id").Where("user_id = ? AND is_default = ?", req.UserID, true)).
+		Delete(&models.FavoriteDish{})
 	if result.RowsAffected == 0 {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Favorite dish not found",
@@ -86,7 +97,7 @@ func DeleteFavoriteDish(c *fiber.Ctx) error {
 }
 
 // @Summary Get user's favorite dishes
-// @Description Get all favorite dishes for the current user
+// @Description Get the dishes in the user's default favorites collection
 // @Tags favorites
 // @Accept json
 // @Produce json
@@ -112,41 +123,56 @@ func GetUserFavoriteDishes(c *fiber.Ctx) error {
 	}
 
 	var favoriteDishes []models.FavoriteDish
-	database.DB.Where("user_id = ?", user.ID).Find(&favoriteDishes)
+	database.DB.
+		Joins("JOIN favorite_collections ON favorite_collections.id = favorite_dishes.collection_id").
+		Where("favorite_collections.user_id = ? AND favorite_collections.is_default = ?", user.ID, true).
+		Order("favorite_dishes.position, favorite_dishes.id").
+		Find(&favoriteDishes)
 
 	var dishesWithIngredients []models.DishWithIngredients
 	for _, favorite := range favoriteDishes {
 		var dish models.Dish
 		database.DB.First(&dish, favorite.DishID)
 
-		dishWithIngredients := models.DishWithIngredients{
-			Dish: dish,
-		}
+		dishesWithIngredients = append(dishesWithIngredients, loadDishWithIngredients(dish))
+	}
 
-		var dishIngredients []models.DishIngredient
-		database.DB.Where("dish_id = ?", dish.ID).Find(&dishIngredients)
+	dishesWithIngredients = applyDietaryProfile(c, dishesWithIngredients, profileModeFlag)
+	dishesWithIngredients = applyDishRatings(dishesWithIngredients)
+	dishesWithIngredients = applyDishCosts(dishesWithIngredients, models.DefaultCurrency)
 
-		for _, di := range dishIngredients {
-			var ingredient models.Ingredient
-			database.DB.First(&ingredient, di.IngredientID)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"favorite_dishes": dishesWithIngredients,
+	})
+}
+
+// loadDishWithIngredients loads a dish's ingredients and tags.
+func loadDishWithIngredients(dish models.Dish) models.DishWithIngredients {
+	dishWithIngredients := models.DishWithIngredients{
+		Dish: dish,
+	}
 
-			var imageBase64 string
-			if len(ingredient.Image) > 0 {
-				imageBase64 = base64.StdEncoding.EncodeToString(ingredient.Image)
-			}
+	var dishIngredients []models.DishIngredient
+	database.DB.Where("dish_id = ?", dish.ID).Find(&dishIngredients)
 
-			dishWithIngredients.Ingredients = append(dishWithIngredients.Ingredients, models.IngredientDetails{
-				ID:       ingredient.ID,
-				Name:     ingredient.Name,
-				Image:    imageBase64,
-				Quantity: di.Quantity,
-			})
+	for _, di := range dishIngredients {
+		var ingredient models.Ingredient
+		database.DB.First(&ingredient, di.IngredientID)
+
+		var imageBase64 string
+		if len(ingredient.Image) > 0 {
+			imageBase64 = base64.StdEncoding.EncodeToString(ingredient.Image)
 		}
 
-		dishesWithIngredients = append(dishesWithIngredients, dishWithIngredients)
+		dishWithIngredients.Ingredients = append(dishWithIngredients.Ingredients, models.IngredientDetails{
+			ID:       ingredient.ID,
+			Name:     ingredient.Name,
+			Image:    imageBase64,
+			Quantity: di.Quantity,
+			Unit:     di.Unit,
+		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"favorite_dishes": dishesWithIngredients,
-	})
+	dishWithIngredients.Tags = loadDishTags(dish.ID)
+	return dishWithIngredients
 }
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"foodapp/database"
+	"foodapp/models"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get nutrition goals
+// @Description Get the current user's daily nutrition goals by effective date, and the one in effect today in their time zone
+// @Tags users
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.NutritionGoalsResponse
+// @Failure 500 {object} map[string]string
+// @Router /users/profile/goals [get]
+func GetNutritionGoals(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	goals, err := loadNutritionGoals(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get goals",
+		})
+	}
+
+	response := models.NutritionGoalsResponse{Goals: []models.NutritionGoalResponse{}}
+	for _, goal := range goals {
+		response.Goals = append(response.Goals, convertNutritionGoalToResponse(goal))
+	}
+	if current := goalOn(goals, localToday(userLocation(userID)).Format(models.MealPlanDateLayout)); current != nil {
+		currentResponse := convertNutritionGoalToResponse(*current)
+		response.Current = &currentResponse
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Set nutrition goal
+// @Description Set daily calorie and macro targets taking effect on a day, today by default. They apply until the next goal takes effect; a goal set for the same day is replaced
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.NutritionGoalRequest true "Effective date and targets"
+// @Success 201 {object} models.NutritionGoalResponse
+// @Success 200 {object} models.NutritionGoalResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/profile/goals [put]
+func SetNutritionGoal(c *fiber.Ctx) error {
+	var req models.NutritionGoalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	targets := req.Targets
+	if targets.Calories <= 0 || targets.Proteins < 0 || targets.Fats < 0 || targets.Carbs < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A positive calorie target is required and macro targets must not be negative",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	effectiveFrom := req.EffectiveFrom
+	if effectiveFrom == "" {
+		effectiveFrom = localToday(userLocation(userID)).Format(models.MealPlanDateLayout)
+	}
+	day, err := parsePlanDate(effectiveFrom)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid effective_from, use YYYY-MM-DD",
+		})
+	}
+
+	goal := models.NutritionGoal{UserID: userID, EffectiveFrom: day}
+	status := fiber.StatusOK
+	if result := database.DB.Where("user_id = ? AND effective_from = ?", userID, day).First(&goal); result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get goals",
+			})
+		}
+		status = fiber.StatusCreated
+	}
+
+	goal.Calories = targets.Calories
+	goal.Fats = targets.Fats
+	goal.Carbs = targets.Carbs
+	goal.Proteins = targets.Proteins
+
+	if result := database.DB.Save(&goal); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save goal",
+		})
+	}
+
+	return c.Status(status).JSON(convertNutritionGoalToResponse(goal))
+}
+
+// @Summary Get progress
+// @Description Compare what the user ate each day with the goal in effect that day. A day is within target when every tracked target is met to within the tolerance. Without from and to the last 7 days are shown
+// @Tags statistics
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Param tolerance query number false "Allowed deviation from each target in percent (default 10)"
+// @Success 200 {object} models.ProgressResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /statistics/progress [get]
+func GetProgress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	location := userLocation(userID)
+
+	tolerance := c.QueryFloat("tolerance", models.DefaultGoalTolerancePercent)
+	if tolerance < 0 || tolerance > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tolerance",
+		})
+	}
+
+	from, to, err := parseDiaryRange(c.Query("from"), c.Query("to"), models.DiaryGroupDay, location)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	periods, bounds := diaryPeriods(from, to, models.DiaryGroupDay)
+	if err := sumIntake(userID, periods, bounds); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch statistics",
+		})
+	}
+
+	goals, err := loadNutritionGoals(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get goals",
+		})
+	}
+
+	response := models.ProgressResponse{
+		Timezone:         location.String(),
+		From:             from.Format(models.MealPlanDateLayout),
+		To:               to.Format(models.MealPlanDateLayout),
+		TolerancePercent: tolerance,
+	}
+
+	// streaks[i] is the run of days within target ending on day i.
+	streaks := make([]int, len(periods))
+	var deltas models.Nutrition
+	for i, period := range periods {
+		day := models.ProgressDay{
+			Date:      period.From,
+			Dishes:    period.Dishes,
+			Nutrition: period.Nutrition,
+		}
+
+		if goal := goalOn(goals, period.From); goal != nil {
+			targets := nutritionGoalTargets(*goal)
+			delta := nutritionDelta(period.Nutrition, targets)
+			day.Goal = &targets
+			day.Delta = &delta
+			day.WithinTarget = withinTarget(period.Nutrition, targets, tolerance)
+
+			response.DaysWithGoal++
+			deltas = addNutrition(deltas, delta)
+		}
+
+		if day.WithinTarget {
+			response.DaysWithinTarget++
+			streaks[i] = 1
+			if i > 0 {
+				streaks[i] += streaks[i-1]
+			}
+			response.LongestStreak = max(response.LongestStreak, streaks[i])
+		}
+		response.Days = append(response.Days, day)
+	}
+
+	last := len(streaks) - 1
+	response.CurrentStreak = streaks[last]
+	if !response.Days[last].WithinTarget && last > 0 && response.To == localToday(location).Format(models.MealPlanDateLayout) {
+		// Today isn't over yet, so it doesn't break the streak.
+		response.CurrentStreak = streaks[last-1]
+	}
+
+	if response.DaysWithGoal > 0 {
+		average := averageNutrition(deltas, response.DaysWithGoal)
+		response.AverageDelta = &average
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func loadNutritionGoals(userID uint) ([]models.NutritionGoal, error) {
+	var goals []models.NutritionGoal
+	if result := database.DB.Where("user_id = ?", userID).Order("effective_from").Find(&goals); result.Error != nil {
+		return nil, result.Error
+	}
+	return goals, nil
+}
+
+// goalOn is the goal in effect on date, in MealPlanDateLayout. goals must be
+// ordered by effective date.
+func goalOn(goals []models.NutritionGoal, date string) *models.NutritionGoal {
+	var current *models.NutritionGoal
+	for i := range goals {
+		if goals[i].EffectiveFrom.Format(models.MealPlanDateLayout) > date {
+			break
+		}
+		current = &goals[i]
+	}
+	return current
+}
+
+func nutritionGoalTargets(goal models.NutritionGoal) models.Nutrition {
+	return models.Nutrition{
+		Calories: goal.Calories,
+		Fats:     goal.Fats,
+		Carbs:    goal.Carbs,
+		Proteins: goal.Proteins,
+	}
+}
+
+func convertNutritionGoalToResponse(goal models.NutritionGoal) models.NutritionGoalResponse {
+	return models.NutritionGoalResponse{
+		ID:            goal.ID,
+		EffectiveFrom: goal.EffectiveFrom.Format(models.MealPlanDateLayout),
+		Targets:       nutritionGoalTargets(goal),
+	}
+}
+
+// withinTarget reports whether actual is within tolerance percent of every
+// target that is set.
+func withinTarget(actual, targets models.Nutrition, tolerance float64) bool {
+	for _, pair := range [][2]float64{
+		{actual.Calories, targets.Calories},
+		{actual.Fats, targets.Fats},
+		{actual.Carbs, targets.Carbs},
+		{actual.Proteins, targets.Proteins},
+	} {
+		if pair[1] > 0 && math.Abs(pair[0]-pair[1]) > pair[1]*tolerance/100 {
+			return false
+		}
+	}
+	return true
+}
+
+// localToday is midnight today in location.
+func localToday(location *time.Location) time.Time {
+	now := time.Now().In(location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+}
@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Create household
+// @Description Create a household owned by the current user
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.HouseholdRequest true "Household name"
+// @Success 201 {object} models.HouseholdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households [post]
+func CreateHousehold(c *fiber.Ctx) error {
+	var req models.HouseholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	household := models.Household{
+		Name:    name,
+		OwnerID: userID,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&household).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: household.ID,
+			UserID:      userID,
+			Role:        models.HouseholdRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create household",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(convertHouseholdToResponse(household, userID))
+}
+
+// @Summary Get households
+// @Description Get the households the current user belongs to
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.HouseholdResponse
+// @Failure 500 {object} map[string]string
+// @Router /households [get]
+func GetHouseholds(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var households []models.Household
+	if result := database.DB.
+		Where("id IN (?)", database.DB.Model(&models.HouseholdMember{}).Select("household_id").Where("user_id = ?", userID)).
+		Order("name, id").
+		Find(&households); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch households",
+		})
+	}
+
+	response := []models.HouseholdResponse{}
+	for _, household := range households {
+		response = append(response, convertHouseholdToResponse(household, userID))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Get household
+// @Description Get a household the current user belongs to, with its members
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Household ID"
+// @Success 200 {object} models.HouseholdResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /households/{id} [get]
+func GetHousehold(c *fiber.Ctx) error {
+	household, ok, err := findHouseholdFromParam(c)
+	if !ok {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(convertHouseholdToResponse(household, c.Locals("userID").(uint)))
+}
+
+// @Summary Delete household
+// @Description Delete a household and its shared cart and purchase history (owner only)
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Household ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id} [delete]
+func DeleteHousehold(c *fiber.Ctx) error {
+	household, ok, err := findHouseholdFromParam(c)
+	if !ok {
+		return err
+	}
+
+	if household.OwnerID != c.Locals("userID").(uint) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the owner can delete the household",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		lineIDs := tx.Model(&models.Cart{}).Select("id").Where("household_id = ?", household.ID)
+		if err := tx.Where("cart_id IN (?)", lineIDs).Delete(&models.CartDishContribution{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("household_id = ?", household.ID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		purchaseIDs := tx.Model(&models.Purchase{}).Select("id").Where("household_id = ?", household.ID)
+		if err := tx.Where("purchase_id IN (?)", purchaseIDs).Delete(&models.PurchaseItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("household_id = ?", household.ID).Delete(&models.Purchase{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("active_household_id = ?", household.ID).Update("active_household_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("household_id = ?", household.ID).Delete(&models.HouseholdInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("household_id = ?", household.ID).Delete(&models.HouseholdMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&household).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete household",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Household deleted successfully",
+	})
+}
+
+// @Summary Invite to household
+// @Description Invite a registered user, by email, to join a household
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Household ID"
+// @Param request body models.HouseholdInviteRequest true "Invitee email"
+// @Success 201 {object} models.HouseholdInvite
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id}/invites [post]
+func InviteToHousehold(c *fiber.Ctx) error {
+	household, ok, err := findHouseholdFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.HouseholdInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var invitee models.User
+	if result := database.DB.Where("email = ?", strings.TrimSpace(req.Email)).First(&invitee); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	if isHouseholdMember(household.ID, invitee.ID) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "User is already a member",
+		})
+	}
+
+	var pending int64
+	database.DB.Model(&models.HouseholdInvite{}).
+		Where("household_id = ? AND invited_user_id = ? AND status = ?", household.ID, invitee.ID, models.InviteStatusPending).
+		Count(&pending)
+	if pending > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "User is already invited",
+		})
+	}
+
+	invite := models.HouseholdInvite{
+		HouseholdID:   household.ID,
+		InvitedUserID: invitee.ID,
+		InvitedBy:     c.Locals("userID").(uint),
+		Status:        models.InviteStatusPending,
+	}
+
+	if result := database.DB.Create(&invite); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to invite user",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(invite)
+}
+
+// @Summary Get household invites
+// @Description Get the pending household invites of the current user
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.HouseholdInvite
+// @Failure 500 {object} map[string]string
+// @Router /households/invites [get]
+func GetHouseholdInvites(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	invites := []models.HouseholdInvite{}
+	if result := database.DB.Where("invited_user_id = ? AND status = ?", userID, models.InviteStatusPending).Order("id").Find(&invites); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch invites",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(invites)
+}
+
+// @Summary Accept household invite
+// @Description Accept a pending invite and join the household
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param invite_id path int true "Invite ID"
+// @Success 200 {object} models.HouseholdResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/invites/{invite_id}/accept [post]
+func AcceptHouseholdInvite(c *fiber.Ctx) error {
+	return answerHouseholdInvite(c, models.InviteStatusAccepted)
+}
+
+// @Summary Decline household invite
+// @Description Decline a pending household invite
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param invite_id path int true "Invite ID"
+// @Success 200 {object} models.HouseholdInvite
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/invites/{invite_id}/decline [post]
+func DeclineHouseholdInvite(c *fiber.Ctx) error {
+	return answerHouseholdInvite(c, models.InviteStatusDeclined)
+}
+
+// @Summary Remove household member
+// @Description Remove a member from a household; the owner can remove anyone else, members can remove themselves
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Household ID"
+// @Param member_id path int true "User ID of the member"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id}/members/{member_id} [delete]
+func RemoveHouseholdMember(c *fiber.Ctx) error {
+	household, ok, err := findHouseholdFromParam(c)
+	if !ok {
+		return err
+	}
+
+	memberID, convErr := strconv.Atoi(c.Params("member_id"))
+	if convErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if uint(memberID) == household.OwnerID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "The owner cannot leave; delete the household instead",
+		})
+	}
+	if userID != household.OwnerID && userID != uint(memberID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the owner can remove other members",
+		})
+	}
+
+	var result *gorm.DB
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result = tx.Where("household_id = ? AND user_id = ?", household.ID, memberID).Delete(&models.HouseholdMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND active_household_id = ?", memberID, household.ID).
+			Update("active_household_id", nil).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove member",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Member not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Member removed successfully",
+	})
+}
+
+// @Summary Select active cart
+// @Description Make a household's shared cart the one the cart endpoints work on; a null household_id selects the user's own cart
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.ActiveHouseholdRequest true "Household to activate"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/active [put]
+func SetActiveHousehold(c *fiber.Ctx) error {
+	var req models.ActiveHouseholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	if req.HouseholdID != nil && !isHouseholdMember(*req.HouseholdID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Household not found",
+		})
+	}
+
+	if result := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("active_household_id", req.HouseholdID); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to select cart",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":             "Active cart updated successfully",
+		"active_household_id": req.HouseholdID,
+	})
+}
+
+func answerHouseholdInvite(c *fiber.Ctx, status string) error {
+	userID := c.Locals("userID").(uint)
+
+	var invite models.HouseholdInvite
+	if result := database.DB.Where("id = ? AND invited_user_id = ? AND status = ?", c.Params("invite_id"), userID, models.InviteStatusPending).First(&invite); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Invite not found",
+		})
+	}
+
+	var household models.Household
+	if result := database.DB.First(&household, invite.HouseholdID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Household not found",
+		})
+	}
+
+	join := status == models.InviteStatusAccepted && !isHouseholdMember(household.ID, userID)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		invite.Status = status
+		if err := tx.Save(&invite).Error; err != nil {
+			return err
+		}
+		if !join {
+			return nil
+		}
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: household.ID,
+			UserID:      userID,
+			Role:        models.HouseholdRoleMember,
+		}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to answer invite",
+		})
+	}
+
+	if status == models.InviteStatusAccepted {
+		return c.Status(fiber.StatusOK).JSON(convertHouseholdToResponse(household, userID))
+	}
+	return c.Status(fiber.StatusOK).JSON(invite)
+}
+
+// findHouseholdFromParam loads the :id household if the current user is a
+// member of it, writing the 400/404 response itself when it cannot.
+func findHouseholdFromParam(c *fiber.Ctx) (household models.Household, ok bool, err error) {
+	householdID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return household, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid household ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if !isHouseholdMember(uint(householdID), userID) || database.DB.First(&household, householdID).Error != nil {
+		return household, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Household not found",
+		})
+	}
+
+	return household, true, nil
+}
+
+func isHouseholdMember(householdID, userID uint) bool {
+	var count int64
+	database.DB.Model(&models.HouseholdMember{}).Where("household_id = ? AND user_id = ?", householdID, userID).Count(&count)
+	return count > 0
+}
+
+func convertHouseholdToResponse(household models.Household, userID uint) models.HouseholdResponse {
+	response := models.HouseholdResponse{
+		ID:        household.ID,
+		Name:      household.Name,
+		OwnerID:   household.OwnerID,
+		Members:   []models.HouseholdMemberResponse{},
+		CreatedAt: household.CreatedAt,
+	}
+
+	var user models.User
+	database.DB.Select("id", "active_household_id").Limit(1).Find(&user, userID)
+	response.Active = user.ActiveHouseholdID != nil && *user.ActiveHouseholdID == household.ID
+
+	var members []models.HouseholdMember
+	database.DB.Where("household_id = ?", household.ID).Order("id").Find(&members)
+	for _, member := range members {
+		var memberUser models.User
+		database.DB.Select("id", "user_name", "email").Limit(1).Find(&memberUser, member.UserID)
+
+		response.Members = append(response.Members, models.HouseholdMemberResponse{
+			UserID:   member.UserID,
+			UserName: memberUser.UserName,
+			Email:    memberUser.Email,
+			Role:     member.Role,
+		})
+	}
+	return response
+}
@@ -3,8 +3,12 @@ package handlers
 import (
 	"foodapp/database"
 	"foodapp/models"
+	"foodapp/utils"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // @Summary Add new ingredient
@@ -16,6 +20,7 @@ import (
 // @Param ingredient body models.IngredientRequest true "Ingredient details"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /ingredients/add [post]
 func AddIngredient(c *fiber.Ctx) error {
@@ -26,12 +31,48 @@ func AddIngredient(c *fiber.Ctx) error {
 		})
 	}
 
+	allergens, err := normalizeAllergens(req.Allergens)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	section, err := normalizeSection(req.Section)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	normalized := utils.NormalizeIngredientName(req.Name)
+	if normalized == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ingredient name is required",
+		})
+	}
+
+	if existingID, found := findIngredientByName(normalized, 0); found {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":         "Ingredient already exists",
+			"ingredient_id": existingID,
+		})
+	}
+
 	ingredient := models.Ingredient{
-		Name:  req.Name,
-		Image: req.Image,
+		Name:           strings.TrimSpace(req.Name),
+		NormalizedName: normalized,
+		Section:        section,
+		Image:          req.Image,
 	}
 
-	if result := database.DB.Create(&ingredient); result.Error != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ingredient).Error; err != nil {
+			return err
+		}
+		return replaceIngredientAllergens(tx, ingredient.ID, allergens)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to add ingredient",
 		})
@@ -42,3 +83,317 @@ func AddIngredient(c *fiber.Ctx) error {
 		"ingredient_id": ingredient.ID,
 	})
 }
+
+const (
+	defaultIngredientPageSize = 20
+	maxIngredientPageSize     = 100
+)
+
+// @Summary Get ingredients
+// @Description Get a page of ingredients ordered by name
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Page size (max 100)"
+// @Success 200 {object} models.IngredientListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients [get]
+func GetIngredients(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", defaultIngredientPageSize)
+	if page < 1 || pageSize < 1 || pageSize > maxIngredientPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid pagination parameters",
+		})
+	}
+
+	var total int64
+	if result := database.DB.Model(&models.Ingredient{}).Count(&total); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get ingredients",
+		})
+	}
+
+	var ingredients []models.Ingredient
+	if result := database.DB.Order("name, id").Limit(pageSize).Offset((page - 1) * pageSize).Find(&ingredients); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get ingredients",
+		})
+	}
+
+	response := models.IngredientListResponse{
+		Items:    []models.IngredientResponse{},
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+	for _, ingredient := range ingredients {
+		response.Items = append(response.Items, convertIngredientToResponse(ingredient))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Search ingredients
+// @Description Find ingredients whose name or a synonym starts with the query, for autocomplete
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Param q query string true "Name prefix"
+// @Param limit query int false "Maximum number of results (max 100)"
+// @Success 200 {array} models.IngredientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/search [get]
+func SearchIngredients(c *fiber.Ctx) error {
+	prefix := strings.TrimSpace(c.Query("q"))
+	if prefix == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Search query parameter is required",
+		})
+	}
+
+	limit := c.QueryInt("limit", 10)
+	if limit < 1 || limit > maxIngredientPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit",
+		})
+	}
+
+	pattern := escapeLike(strings.ToLower(prefix)) + "%"
+	synonymMatches := database.DB.Model(&models.IngredientSynonym{}).
+		Select("ingredient_id").
+		Where("LOWER(name) LIKE ? ESCAPE '\\'", pattern)
+
+	var ingredients []models.Ingredient
+	if result := database.DB.Where("LOWER(name) LIKE ? ESCAPE '\\'", pattern).
+		Or("id IN (?)", synonymMatches).
+		Order("name").Limit(limit).Find(&ingredients); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search ingredients",
+		})
+	}
+
+	response := []models.IngredientResponse{}
+	for _, ingredient := range ingredients {
+		response = append(response, convertIngredientToResponse(ingredient))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Get ingredient
+// @Description Get an ingredient by ID
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Param id path int true "Ingredient ID"
+// @Success 200 {object} models.IngredientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /ingredients/{id} [get]
+func GetIngredient(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	response := convertIngredientToResponse(ingredient)
+	response.Allergens = loadIngredientAllergens(ingredient.ID)
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Update ingredient
+// @Description Rename an ingredient or replace its image and allergens
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param ingredient body models.IngredientRequest true "Ingredient details"
+// @Success 200 {object} models.IngredientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id} [put]
+func UpdateIngredient(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var req models.IngredientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ingredient name is required",
+		})
+	}
+
+	allergens, err := normalizeAllergens(req.Allergens)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	section, err := normalizeSection(req.Section)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	normalized := utils.NormalizeIngredientName(req.Name)
+	if existingID, found := findIngredientByName(normalized, ingredient.ID); found {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":         "Ingredient already exists",
+			"ingredient_id": existingID,
+		})
+	}
+
+	ingredient.Name = strings.TrimSpace(req.Name)
+	ingredient.NormalizedName = normalized
+	if section != "" {
+		ingredient.Section = section
+	}
+	if req.Image != nil {
+		ingredient.Image = req.Image
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&ingredient).Error; err != nil {
+			return err
+		}
+		if req.Allergens != nil {
+			return replaceIngredientAllergens(tx, ingredient.ID, allergens)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update ingredient",
+		})
+	}
+
+	response := convertIngredientToResponse(ingredient)
+	response.Allergens = loadIngredientAllergens(ingredient.ID)
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Delete ingredient
+// @Description Delete an ingredient. Refuses while dishes or carts still use it unless force=true, which removes those references too
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param force query bool false "Also remove the ingredient from dishes and carts"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id} [delete]
+func DeleteIngredient(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	var dishCount, cartCount int64
+	database.DB.Model(&models.DishIngredient{}).Where("ingredient_id = ?", ingredient.ID).Distinct("dish_id").Count(&dishCount)
+	database.DB.Model(&models.Cart{}).Where("ingredient_id = ?", ingredient.ID).Count(&cartCount)
+
+	if (dishCount > 0 || cartCount > 0) && !c.QueryBool("force") {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Ingredient is still in use",
+			"dishes": dishCount,
+			"carts":  cartCount,
+		})
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteIngredient(tx, ingredient.ID)
+	}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete ingredient",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Ingredient deleted successfully",
+	})
+}
+
+// deleteIngredient removes an ingredient together with every row pointing at it.
+// Purchase history is kept, it has its own copy of the ingredient name.
+func deleteIngredient(tx *gorm.DB, ingredientID uint) error {
+	lineIDs := tx.Model(&models.Cart{}).Select("id").Where("ingredient_id = ?", ingredientID)
+	if err := tx.Where("cart_id IN (?)", lineIDs).Delete(&models.CartDishContribution{}).Error; err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{
+		&models.Cart{},
+		&models.DishIngredient{},
+		&models.DishStepIngredient{},
+		&models.IngredientAllergen{},
+		&models.IngredientSynonym{},
+		&models.IngredientPrice{},
+		&models.PantryItem{},
+	} {
+		if err := tx.Where("ingredient_id = ?", ingredientID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("ingredient_id = ? OR substitute_id = ?", ingredientID, ingredientID).
+		Delete(&models.IngredientSubstitution{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&models.Ingredient{}, ingredientID).Error
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"foodapp/utils"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get ingredient synonyms
+// @Description Get the alternative names and translations of an ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Param id path int true "Ingredient ID"
+// @Success 200 {array} models.IngredientSynonym
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/synonyms [get]
+func GetIngredientSynonyms(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	synonyms := []models.IngredientSynonym{}
+	if result := database.DB.Where("ingredient_id = ?", ingredientID).Order("language, name").Find(&synonyms); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch synonyms",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(synonyms)
+}
+
+// @Summary Add ingredient synonym
+// @Description Add an alternative name or translation for an ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param request body models.IngredientSynonymRequest true "Synonym"
+// @Success 201 {object} models.IngredientSynonym
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/synonyms [post]
+func AddIngredientSynonym(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var req models.IngredientSynonymRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	normalized := utils.NormalizeIngredientName(req.Name)
+	if normalized == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Synonym name is required",
+		})
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	if existingID, found := findIngredientByName(normalized, 0); found {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":         "Name already used by an ingredient",
+			"ingredient_id": existingID,
+		})
+	}
+
+	synonym := models.IngredientSynonym{
+		IngredientID:   ingredient.ID,
+		Name:           strings.TrimSpace(req.Name),
+		NormalizedName: normalized,
+		Language:       strings.ToLower(strings.TrimSpace(req.Language)),
+	}
+
+	if result := database.DB.Create(&synonym); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add synonym",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(synonym)
+}
+
+// @Summary Delete ingredient synonym
+// @Description Remove a synonym from an ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param synonym_id path int true "Synonym ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/synonyms/{synonym_id} [delete]
+func DeleteIngredientSynonym(c *fiber.Ctx) error {
+	result := database.DB.Where("id = ? AND ingredient_id = ?", c.Params("synonym_id"), c.Params("id")).Delete(&models.IngredientSynonym{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete synonym",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Synonym not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Synonym deleted successfully",
+	})
+}
+
+// @Summary Get likely duplicate ingredients
+// @Description List groups of ingredients whose names or synonyms normalize to the same name
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.DuplicateIngredientGroup
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/duplicates [get]
+func GetDuplicateIngredients(c *fiber.Ctx) error {
+	var ingredients []models.Ingredient
+	if result := database.DB.Select("id", "name", "normalized_name").Find(&ingredients); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch ingredients",
+		})
+	}
+
+	var synonyms []models.IngredientSynonym
+	if result := database.DB.Find(&synonyms); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch synonyms",
+		})
+	}
+
+	byID := make(map[uint]models.Ingredient, len(ingredients))
+	members := make(map[string][]uint)
+	add := func(name string, ingredientID uint) {
+		for _, id := range members[name] {
+			if id == ingredientID {
+				return
+			}
+		}
+		members[name] = append(members[name], ingredientID)
+	}
+
+	for _, ingredient := range ingredients {
+		byID[ingredient.ID] = ingredient
+		name := ingredient.NormalizedName
+		if name == "" {
+			name = utils.NormalizeIngredientName(ingredient.Name)
+		}
+		add(name, ingredient.ID)
+	}
+	for _, synonym := range synonyms {
+		if _, ok := byID[synonym.IngredientID]; ok {
+			add(synonym.NormalizedName, synonym.IngredientID)
+		}
+	}
+
+	groups := []models.DuplicateIngredientGroup{}
+	for name, ids := range members {
+		if len(ids) < 2 {
+			continue
+		}
+
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		group := models.DuplicateIngredientGroup{NormalizedName: name}
+		for _, id := range ids {
+			group.Ingredients = append(group.Ingredients, models.IngredientResponse{
+				ID:   id,
+				Name: byID[id].Name,
+			})
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].NormalizedName < groups[j].NormalizedName })
+
+	return c.Status(fiber.StatusOK).JSON(groups)
+}
+
+// @Summary Merge duplicate ingredients
+// @Description Repoint every dish, cart and related row from the duplicates to the canonical ingredient and delete the duplicates. Cart lines join a canonical line whose unit they convert into, and the carts' clients are told of the change
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.IngredientMergeRequest true "Canonical and duplicate ingredient IDs"
+// @Success 200 {object} models.IngredientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/merge [post]
+func MergeIngredients(c *fiber.Ctx) error {
+	var req models.IngredientMergeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	duplicateIDs := uniqueIDs(req.DuplicateIDs)
+	if req.CanonicalID == 0 || len(duplicateIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Canonical and duplicate ingredient IDs are required",
+		})
+	}
+
+	for _, id := range duplicateIDs {
+		if id == req.CanonicalID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "An ingredient cannot be merged into itself",
+			})
+		}
+	}
+
+	var canonical models.Ingredient
+	if result := database.DB.First(&canonical, req.CanonicalID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Canonical ingredient not found",
+		})
+	}
+
+	var duplicates []models.Ingredient
+	database.DB.Where("id IN ?", duplicateIDs).Find(&duplicates)
+	if len(duplicates) != len(duplicateIDs) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Duplicate ingredient not found",
+		})
+	}
+
+	var events []pendingCartEvent
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, duplicate := range duplicates {
+			merged, err := mergeIngredient(tx, canonical, duplicate)
+			if err != nil {
+				return err
+			}
+			events = append(events, merged...)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to merge ingredients",
+		})
+	}
+
+	for _, pending := range events {
+		publishCartEvent(pending.owner, pending.event)
+	}
+
+	response := convertIngredientToResponse(canonical)
+	response.Allergens = loadIngredientAllergens(canonical.ID)
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// mergeIngredient moves everything that references duplicate onto canonical,
+// keeps the duplicate's name as a synonym and deletes the duplicate. It
+// returns the events for the cart lines it changed.
+func mergeIngredient(tx *gorm.DB, canonical, duplicate models.Ingredient) ([]pendingCartEvent, error) {
+	var dishIngredients []models.DishIngredient
+	if err := tx.Where("ingredient_id = ?", duplicate.ID).Find(&dishIngredients).Error; err != nil {
+		return nil, err
+	}
+	for _, di := range dishIngredients {
+		var existing models.DishIngredient
+		found := tx.Where("dish_id = ? AND ingredient_id = ? AND unit = ?", di.DishID, canonical.ID, di.Unit).Limit(1).Find(&existing)
+		if found.Error != nil {
+			return nil, found.Error
+		}
+
+		if found.RowsAffected > 0 {
+			existing.Quantity += di.Quantity
+			if err := tx.Save(&existing).Error; err != nil {
+				return nil, err
+			}
+			if err := tx.Delete(&di).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		di.IngredientID = canonical.ID
+		if err := tx.Save(&di).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var lines []models.Cart
+	if err := tx.Where("ingredient_id = ?", duplicate.ID).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	var events []pendingCartEvent
+	for _, line := range lines {
+		_, moved, err := moveCartLine(tx, line, canonical.ID, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range moved {
+			events = append(events, pendingCartEvent{owner: cartOwnerOf(line), event: event})
+		}
+	}
+
+	canonicalSteps := tx.Model(&models.DishStepIngredient{}).Select("step_id").Where("ingredient_id = ?", canonical.ID)
+	if err := tx.Where("ingredient_id = ? AND step_id IN (?)", duplicate.ID, canonicalSteps).Delete(&models.DishStepIngredient{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.DishStepIngredient{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+
+	canonicalAllergens := tx.Model(&models.IngredientAllergen{}).Select("allergen").Where("ingredient_id = ?", canonical.ID)
+	if err := tx.Where("ingredient_id = ? AND allergen IN (?)", duplicate.ID, canonicalAllergens).Delete(&models.IngredientAllergen{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.IngredientAllergen{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.IngredientSubstitution{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.IngredientSubstitution{}).Where("substitute_id = ?", duplicate.ID).Update("substitute_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("ingredient_id = substitute_id").Delete(&models.IngredientSubstitution{}).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.IngredientSynonym{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.PurchaseItem{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.IngredientPrice{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.PantryItem{}).Where("ingredient_id = ?", duplicate.ID).Update("ingredient_id", canonical.ID).Error; err != nil {
+		return nil, err
+	}
+
+	normalized := utils.NormalizeIngredientName(duplicate.Name)
+	if normalized != "" && normalized != canonical.NormalizedName {
+		synonym := models.IngredientSynonym{
+			IngredientID:   canonical.ID,
+			Name:           duplicate.Name,
+			NormalizedName: normalized,
+		}
+		if err := tx.Where("normalized_name = ?", normalized).FirstOrCreate(&synonym).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return events, tx.Delete(&models.Ingredient{}, duplicate.ID).Error
+}
+
+// findIngredientByName looks for another ingredient whose name or synonym
+// normalizes to the given name.
+func findIngredientByName(normalized string, excludeID uint) (uint, bool) {
+	var ingredient models.Ingredient
+	if result := database.DB.Where("normalized_name = ? AND id <> ?", normalized, excludeID).Limit(1).Find(&ingredient); result.RowsAffected > 0 {
+		return ingredient.ID, true
+	}
+
+	var synonym models.IngredientSynonym
+	if result := database.DB.Where("normalized_name = ? AND ingredient_id <> ?", normalized, excludeID).Limit(1).Find(&synonym); result.RowsAffected > 0 {
+		return synonym.IngredientID, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,925 @@
+package handlers
+
+import (
+	"errors"
+	"foodapp/database"
+	"foodapp/models"
+	"foodapp/service"
+	"foodapp/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxPlanRangeDays caps how many days one meal plan request may span.
+const maxPlanRangeDays = 92
+
+// The calendar feed holds the meals planned from feedPastDays ago to
+// feedFutureDays ahead. Dishes without a preparation time are shown for
+// feedDefaultDuration.
+const (
+	feedPastDays        = 28
+	feedFutureDays      = 91
+	feedDefaultDuration = 30 * time.Minute
+)
+
+// mealSlotTimes is when each meal slot starts in the calendar feed.
+var mealSlotTimes = map[string]time.Duration{
+	models.MealSlotBreakfast: 8 * time.Hour,
+	models.MealSlotLunch:     12*time.Hour + 30*time.Minute,
+	models.MealSlotSnack:     16 * time.Hour,
+	models.MealSlotDinner:    19 * time.Hour,
+}
+
+// @Summary Get meal plan
+// @Description Get the current user's planned meals for a range of days, the current week by default
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param week query string false "Any day of the week to get, instead of from and to"
+// @Success 200 {array} models.MealPlanEntryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans [get]
+func GetMealPlan(c *fiber.Ctx) error {
+	from, to, err := parsePlanRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	entries, err := loadMealPlan(c.Locals("userID").(uint), from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entries)
+}
+
+// @Summary Add meal plan entry
+// @Description Plan a dish for a meal slot of a day
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.MealPlanRequest true "Meal plan entry"
+// @Success 201 {object} models.MealPlanEntryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans [post]
+func AddMealPlanEntry(c *fiber.Ctx) error {
+	var req models.MealPlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	entry := models.MealPlanEntry{UserID: c.Locals("userID").(uint)}
+	return saveMealPlanEntry(c, entry, req, fiber.StatusCreated)
+}
+
+// @Summary Update meal plan entry
+// @Description Move a planned meal to another day or slot, or change its dish or servings
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Meal plan entry ID"
+// @Param request body models.MealPlanRequest true "Meal plan entry"
+// @Success 200 {object} models.MealPlanEntryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/{id} [put]
+func UpdateMealPlanEntry(c *fiber.Ctx) error {
+	entry, ok, err := findMealPlanEntryFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.MealPlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	return saveMealPlanEntry(c, entry, req, fiber.StatusOK)
+}
+
+// @Summary Delete meal plan entry
+// @Description Remove a planned meal
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Meal plan entry ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/{id} [delete]
+func DeleteMealPlanEntry(c *fiber.Ctx) error {
+	entry, ok, err := findMealPlanEntryFromParam(c)
+	if !ok {
+		return err
+	}
+
+	if result := database.DB.Delete(&entry); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete meal plan entry",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Meal plan entry deleted successfully",
+	})
+}
+
+// @Summary Clear meal plan
+// @Description Remove every planned meal in a range of days. The range must be given
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param week query string false "Any day of the week to clear, instead of from and to"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans [delete]
+func ClearMealPlan(c *fiber.Ctx) error {
+	if c.Query("week") == "" && (c.Query("from") == "" || c.Query("to") == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "from and to, or week, are required",
+		})
+	}
+
+	from, to, err := parsePlanRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	result := database.DB.Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Delete(&models.MealPlanEntry{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to clear meal plan",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Meal plan cleared successfully",
+		"deleted": result.RowsAffected,
+	})
+}
+
+// @Summary Copy meal plan week
+// @Description Copy the meals planned in one week (Monday to Sunday) to another week, the following one by default
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.MealPlanCopyRequest true "Weeks to copy from and to"
+// @Success 201 {array} models.MealPlanEntryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/copy-week [post]
+func CopyMealPlanWeek(c *fiber.Ctx) error {
+	var req models.MealPlanCopyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	fromDay, err := parsePlanDate(req.FromWeek)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid from_week, use YYYY-MM-DD",
+		})
+	}
+	source := weekStart(fromDay)
+
+	target := source.AddDate(0, 0, 7)
+	if req.ToWeek != "" {
+		toDay, err := parsePlanDate(req.ToWeek)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid to_week, use YYYY-MM-DD",
+			})
+		}
+		target = weekStart(toDay)
+	}
+	if target.Equal(source) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot copy a week onto itself",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	var entries []models.MealPlanEntry
+	if result := database.DB.Where("user_id = ? AND date >= ? AND date < ?", userID, source, source.AddDate(0, 0, 7)).Order("date, id").Find(&entries); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to copy meal plan",
+		})
+	}
+
+	offset := int(target.Sub(source).Hours() / 24)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if req.Replace {
+			if err := tx.Where("user_id = ? AND date >= ? AND date < ?", userID, target, target.AddDate(0, 0, 7)).Delete(&models.MealPlanEntry{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, entry := range entries {
+			copied := models.MealPlanEntry{
+				UserID:   userID,
+				Date:     entry.Date.AddDate(0, 0, offset),
+				Slot:     entry.Slot,
+				DishID:   entry.DishID,
+				Servings: entry.Servings,
+			}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to copy meal plan",
+		})
+	}
+
+	copied, err := loadMealPlan(userID, target, target.AddDate(0, 0, 6))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(copied)
+}
+
+// @Summary Get meal plan nutrition summary
+// @Description Total the nutrition of the planned meals per day and over a range of days, the current week by default
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param week query string false "Any day of the week to summarize, instead of from and to"
+// @Success 200 {object} models.MealPlanSummary
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/summary [get]
+func GetMealPlanSummary(c *fiber.Ctx) error {
+	from, to, err := parsePlanRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	entries, err := loadMealPlan(c.Locals("userID").(uint), from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan",
+		})
+	}
+
+	summary := models.MealPlanSummary{
+		From: from.Format(models.MealPlanDateLayout),
+		To:   to.Format(models.MealPlanDateLayout),
+		Days: summarizeMealPlanDays(entries),
+	}
+	for _, day := range summary.Days {
+		summary.Total = addNutrition(summary.Total, day.Nutrition)
+	}
+
+	summary.DailyAverage = averageNutrition(summary.Total, planDays(from, to))
+
+	return c.Status(fiber.StatusOK).JSON(summary)
+}
+
+// @Summary Generate meal plan
+// @Description Fill the meal slots of a range of days with catalogue dishes that come close to daily calorie and macro targets, skipping excluded tags and allergens (and the user's own allergens) and dishes that take too long. The same seed gives the same plan
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.MealPlanGenerateRequest true "Range, targets and constraints"
+// @Success 201 {object} models.MealPlanGenerateResponse
+// @Success 200 {object} models.MealPlanGenerateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/generate [post]
+func GenerateMealPlan(c *fiber.Ctx) error {
+	var req models.MealPlanGenerateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	from, to, err := parsePlanDates(req.From, req.To)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	slots := []string{models.MealSlotBreakfast, models.MealSlotLunch, models.MealSlotDinner}
+	if len(req.Slots) > 0 {
+		slots = nil
+		for _, value := range req.Slots {
+			slot, err := normalizeMealSlot(value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			slots = append(slots, slot)
+		}
+		slots = uniqueStrings(slots)
+		sort.SliceStable(slots, func(i, j int) bool { return mealSlotIndex(slots[i]) < mealSlotIndex(slots[j]) })
+	}
+
+	targets := req.Targets
+	if targets.Calories <= 0 || targets.Proteins < 0 || targets.Fats < 0 || targets.Carbs < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A positive calorie target is required and macro targets must not be negative",
+		})
+	}
+
+	if req.MaxPrepTime < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid max_prep_time",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	excludedAllergens, err := normalizeAllergens(req.ExcludeAllergens)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	avoid := make(map[string]bool)
+	for _, allergen := range append(excludedAllergens, loadDietaryProfile(userID).Allergens...) {
+		avoid[allergen] = true
+	}
+
+	var excludedTags []string
+	for _, name := range req.ExcludeTags {
+		if name = normalizeTagName(name); name != "" {
+			excludedTags = append(excludedTags, name)
+		}
+	}
+
+	query := database.DB.Omit("image", "video_instructions").Where("calories > 0")
+	if req.MaxPrepTime > 0 {
+		query = query.Where("preparation_time <= ?", req.MaxPrepTime)
+	}
+	if len(excludedTags) > 0 {
+		tagged := database.DB.Model(&models.DishTag{}).
+			Select("dish_tags.dish_id").
+			Joins("JOIN tags ON tags.id = dish_tags.tag_id").
+			Where("tags.name IN ?", excludedTags)
+		query = query.Where("dishes.id NOT IN (?)", tagged)
+	}
+
+	var catalogue []models.Dish
+	if result := query.Order("id").Find(&catalogue); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get dishes",
+		})
+	}
+
+	dishIDs := make([]uint, 0, len(catalogue))
+	for _, dish := range catalogue {
+		dishIDs = append(dishIDs, dish.ID)
+	}
+	allergens := dishAllergens(dishIDs)
+
+	var candidates []models.Dish
+	dishByID := make(map[uint]models.Dish)
+candidate:
+	for _, dish := range catalogue {
+		for _, allergen := range allergens[dish.ID] {
+			if avoid[allergen] {
+				continue candidate
+			}
+		}
+		candidates = append(candidates, dish)
+		dishByID[dish.ID] = dish
+	}
+
+	if len(candidates) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No dishes match the constraints",
+		})
+	}
+
+	existing, err := loadMealPlan(userID, from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan",
+		})
+	}
+
+	replaced := make(map[string]bool)
+	for _, slot := range slots {
+		replaced[slot] = req.Replace
+	}
+
+	var kept []models.MealPlanEntryResponse
+	planned := make(map[string]models.Nutrition)
+	taken := make(map[string]bool)
+	for _, entry := range existing {
+		if replaced[entry.Slot] {
+			continue
+		}
+		kept = append(kept, entry)
+		planned[entry.Date] = addNutrition(planned[entry.Date], entry.Nutrition)
+		taken[entry.Date+" "+entry.Slot] = true
+	}
+
+	var days []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	generated := service.GenerateMealPlan(service.MealPlanGeneration{
+		Dishes:  candidates,
+		Days:    days,
+		Slots:   slots,
+		Targets: targets,
+		Planned: planned,
+		Taken:   taken,
+		Seed:    seed,
+	})
+
+	status := fiber.StatusOK
+	if !req.DryRun {
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			if req.Replace {
+				if err := tx.Where("user_id = ? AND date >= ? AND date <= ? AND slot IN ?", userID, from, to, slots).Delete(&models.MealPlanEntry{}).Error; err != nil {
+					return err
+				}
+			}
+			for i := range generated {
+				generated[i].UserID = userID
+				if err := tx.Create(&generated[i]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to save meal plan",
+			})
+		}
+		status = fiber.StatusCreated
+	}
+
+	entries := []models.MealPlanEntryResponse{}
+	for _, entry := range generated {
+		entries = append(entries, convertMealPlanEntryToResponse(entry, dishByID[entry.DishID]))
+	}
+
+	plan := append(kept, entries...)
+	sort.SliceStable(plan, func(i, j int) bool {
+		if plan[i].Date != plan[j].Date {
+			return plan[i].Date < plan[j].Date
+		}
+		return mealSlotIndex(plan[i].Slot) < mealSlotIndex(plan[j].Slot)
+	})
+
+	summary := summarizeMealPlanDays(plan)
+	for i, day := range summary {
+		deviation := nutritionDelta(day.Nutrition, targets)
+		summary[i].Deviation = &deviation
+	}
+
+	return c.Status(status).JSON(models.MealPlanGenerateResponse{
+		Seed:    seed,
+		DryRun:  req.DryRun,
+		Targets: targets,
+		Entries: entries,
+		Days:    summary,
+	})
+}
+
+// @Summary Get meal plan feed URL
+// @Description Get the secret URL of the current user's meal plan calendar feed, creating it on first use
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.MealPlanFeedResponse
+// @Failure 500 {object} map[string]string
+// @Router /plans/feed [get]
+func GetMealPlanFeedURL(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var feed models.MealPlanFeed
+	found := database.DB.Where("user_id = ?", userID).Limit(1).Find(&feed)
+	if found.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan feed",
+		})
+	}
+
+	if found.RowsAffected == 0 {
+		token, err := newShareToken()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create meal plan feed",
+			})
+		}
+		feed = models.MealPlanFeed{UserID: userID, Token: token}
+		if result := database.DB.Create(&feed); result.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create meal plan feed",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.MealPlanFeedResponse{
+		URL:       c.BaseURL() + "/plans/feed.ics?token=" + feed.Token,
+		CreatedAt: feed.CreatedAt,
+	})
+}
+
+// @Summary Revoke meal plan feed URL
+// @Description Stop the current user's meal plan feed URL from working. The next request for the URL makes a new one
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/feed [delete]
+func RevokeMealPlanFeed(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	result := database.DB.Where("user_id = ?", userID).Delete(&models.MealPlanFeed{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke meal plan feed",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Meal plan feed not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Meal plan feed revoked successfully",
+	})
+}
+
+// @Summary Get meal plan calendar
+// @Description Get a user's planned meals as an iCalendar feed for calendar apps; the secret token replaces signing in
+// @Tags plans
+// @Produce text/calendar
+// @Param token query string true "Feed token"
+// @Success 200 {string} string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /plans/feed.ics [get]
+func GetMealPlanFeed(c *fiber.Ctx) error {
+	token := c.Query("token")
+
+	var feed models.MealPlanFeed
+	if token == "" || database.DB.Where("token = ?", token).Limit(1).Find(&feed).RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Meal plan feed not found",
+		})
+	}
+
+	from := today().AddDate(0, 0, -feedPastDays)
+	to := today().AddDate(0, 0, feedFutureDays)
+
+	var entries []models.MealPlanEntry
+	if result := database.DB.Where("user_id = ? AND date >= ? AND date <= ?", feed.UserID, from, to).Order("date, id").Find(&entries); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get meal plan",
+		})
+	}
+
+	dishIDs := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		dishIDs = append(dishIDs, entry.DishID)
+	}
+	var dishes []models.Dish
+	database.DB.Select("id", "name", "preparation_time").Where("id IN ?", dishIDs).Find(&dishes)
+	dishByID := make(map[uint]models.Dish, len(dishes))
+	for _, dish := range dishes {
+		dishByID[dish.ID] = dish
+	}
+
+	events := make([]utils.ICalEvent, 0, len(entries))
+	for _, entry := range entries {
+		dish := dishByID[entry.DishID]
+
+		duration := time.Duration(dish.PreparationTime) * time.Minute
+		if duration <= 0 {
+			duration = feedDefaultDuration
+		}
+
+		events = append(events, utils.ICalEvent{
+			UID:         "meal-plan-" + strconv.Itoa(int(entry.ID)) + "@foodapp",
+			Summary:     dish.Name,
+			Description: mealPlanEventDescription(entry),
+			URL:         c.BaseURL() + "/dishes-ingredients/" + strconv.Itoa(int(entry.DishID)) + "?servings=" + strconv.Itoa(entry.Servings),
+			Start:       entry.Date.Add(mealSlotTimes[entry.Slot]),
+			Duration:    duration,
+			Modified:    entry.UpdatedAt,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	return c.Status(fiber.StatusOK).Send(utils.ICalendar("Meal plan", events))
+}
+
+func mealPlanEventDescription(entry models.MealPlanEntry) string {
+	slot := strings.ToUpper(entry.Slot[:1]) + entry.Slot[1:]
+	if entry.Servings == 1 {
+		return slot + ", 1 serving"
+	}
+	return slot + ", " + strconv.Itoa(entry.Servings) + " servings"
+}
+
+// saveMealPlanEntry applies req to entry, saves it and writes the response
+// with status.
+func saveMealPlanEntry(c *fiber.Ctx, entry models.MealPlanEntry, req models.MealPlanRequest, status int) error {
+	date, err := parsePlanDate(req.Date)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date, use YYYY-MM-DD",
+		})
+	}
+
+	slot, err := normalizeMealSlot(req.Slot)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	servings := req.Servings
+	if servings == 0 {
+		servings = 1
+	}
+	if servings < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Servings must be positive",
+		})
+	}
+
+	var dish models.Dish
+	if result := database.DB.First(&dish, req.DishID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dish not found",
+		})
+	}
+
+	entry.Date = date
+	entry.Slot = slot
+	entry.DishID = dish.ID
+	entry.Servings = servings
+
+	if result := database.DB.Save(&entry); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save meal plan entry",
+		})
+	}
+
+	return c.Status(status).JSON(convertMealPlanEntryToResponse(entry, dish))
+}
+
+func findMealPlanEntryFromParam(c *fiber.Ctx) (entry models.MealPlanEntry, ok bool, err error) {
+	entryID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return entry, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid meal plan entry ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if result := database.DB.Where("id = ? AND user_id = ?", entryID, userID).First(&entry); result.Error != nil {
+		return entry, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Meal plan entry not found",
+		})
+	}
+
+	return entry, true, nil
+}
+
+func convertMealPlanEntryToResponse(entry models.MealPlanEntry, dish models.Dish) models.MealPlanEntryResponse {
+	return models.MealPlanEntryResponse{
+		ID:        entry.ID,
+		Date:      entry.Date.Format(models.MealPlanDateLayout),
+		Slot:      entry.Slot,
+		DishID:    entry.DishID,
+		DishName:  dish.Name,
+		Servings:  entry.Servings,
+		Nutrition: mealNutrition(dish, entry.Servings),
+	}
+}
+
+// loadMealPlan loads the user's entries from the day from to the day to, both
+// included, ordered by day, slot and when they were planned.
+func loadMealPlan(userID uint, from, to time.Time) ([]models.MealPlanEntryResponse, error) {
+	var entries []models.MealPlanEntry
+	if err := database.DB.Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Order("date, id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	dishIDs := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		dishIDs = append(dishIDs, entry.DishID)
+	}
+	var dishes []models.Dish
+	if err := database.DB.Omit("image", "video_instructions").Where("id IN ?", dishIDs).Find(&dishes).Error; err != nil {
+		return nil, err
+	}
+	dishByID := make(map[uint]models.Dish, len(dishes))
+	for _, dish := range dishes {
+		dishByID[dish.ID] = dish
+	}
+
+	response := []models.MealPlanEntryResponse{}
+	for _, entry := range entries {
+		response = append(response, convertMealPlanEntryToResponse(entry, dishByID[entry.DishID]))
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		if response[i].Date != response[j].Date {
+			return response[i].Date < response[j].Date
+		}
+		return mealSlotIndex(response[i].Slot) < mealSlotIndex(response[j].Slot)
+	})
+	return response, nil
+}
+
+// summarizeMealPlanDays totals the nutrition of entries per day. entries must
+// be ordered by day.
+func summarizeMealPlanDays(entries []models.MealPlanEntryResponse) []models.MealPlanDaySummary {
+	days := []models.MealPlanDaySummary{}
+	for _, entry := range entries {
+		if len(days) == 0 || days[len(days)-1].Date != entry.Date {
+			days = append(days, models.MealPlanDaySummary{Date: entry.Date})
+		}
+		day := &days[len(days)-1]
+		day.Meals++
+		day.Nutrition = addNutrition(day.Nutrition, entry.Nutrition)
+	}
+	return days
+}
+
+// mealNutrition is the nutrition of servings servings of dish.
+func mealNutrition(dish models.Dish, servings int) models.Nutrition {
+	return models.Nutrition{
+		Calories: float64(dish.Calories * servings),
+		Fats:     float64(dish.Fats * servings),
+		Carbs:    float64(dish.Carbs * servings),
+		Proteins: float64(dish.Proteins * servings),
+	}
+}
+
+func addNutrition(a, b models.Nutrition) models.Nutrition {
+	return models.Nutrition{
+		Calories: utils.RoundTo(a.Calories+b.Calories, 1),
+		Fats:     utils.RoundTo(a.Fats+b.Fats, 1),
+		Carbs:    utils.RoundTo(a.Carbs+b.Carbs, 1),
+		Proteins: utils.RoundTo(a.Proteins+b.Proteins, 1),
+	}
+}
+
+// averageNutrition divides total over count days.
+func averageNutrition(total models.Nutrition, count int) models.Nutrition {
+	days := float64(count)
+	return models.Nutrition{
+		Calories: utils.RoundTo(total.Calories/days, 1),
+		Fats:     utils.RoundTo(total.Fats/days, 1),
+		Carbs:    utils.RoundTo(total.Carbs/days, 1),
+		Proteins: utils.RoundTo(total.Proteins/days, 1),
+	}
+}
+
+// nutritionDelta is actual minus targets, for the targets that are set.
+func nutritionDelta(actual, targets models.Nutrition) models.Nutrition {
+	var delta models.Nutrition
+	if targets.Calories > 0 {
+		delta.Calories = utils.RoundTo(actual.Calories-targets.Calories, 1)
+	}
+	if targets.Fats > 0 {
+		delta.Fats = utils.RoundTo(actual.Fats-targets.Fats, 1)
+	}
+	if targets.Carbs > 0 {
+		delta.Carbs = utils.RoundTo(actual.Carbs-targets.Carbs, 1)
+	}
+	if targets.Proteins > 0 {
+		delta.Proteins = utils.RoundTo(actual.Proteins-targets.Proteins, 1)
+	}
+	return delta
+}
+
+// parsePlanRange reads the days a plan request covers: the week containing
+// ?week, else ?from to ?to, else the current week.
+func parsePlanRange(c *fiber.Ctx) (from, to time.Time, err error) {
+	if week := c.Query("week"); week != "" {
+		day, err := parsePlanDate(week)
+		if err != nil {
+			return from, to, errors.New("Invalid week, use YYYY-MM-DD")
+		}
+		from = weekStart(day)
+		return from, from.AddDate(0, 0, 6), nil
+	}
+
+	if c.Query("from") == "" && c.Query("to") == "" {
+		from = weekStart(today())
+		return from, from.AddDate(0, 0, 6), nil
+	}
+
+	return parsePlanDates(c.Query("from"), c.Query("to"))
+}
+
+// parsePlanDates parses the first and last day of a plan range and checks the
+// range is in order and not too long.
+func parsePlanDates(fromValue, toValue string) (from, to time.Time, err error) {
+	if from, err = parsePlanDate(fromValue); err != nil {
+		return from, to, errors.New("Invalid from, use YYYY-MM-DD")
+	}
+	if to, err = parsePlanDate(toValue); err != nil {
+		return from, to, errors.New("Invalid to, use YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+	if planDays(from, to) > maxPlanRangeDays {
+		return from, to, errors.New("Range must not exceed " + strconv.Itoa(maxPlanRangeDays) + " days")
+	}
+	return from, to, nil
+}
+
+func parsePlanDate(value string) (time.Time, error) {
+	return time.Parse(models.MealPlanDateLayout, strings.TrimSpace(value))
+}
+
+// planDays counts the days from from to to, both included.
+func planDays(from, to time.Time) int {
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+// weekStart is the Monday of day's week.
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// normalizeMealSlot lower-cases a meal slot and checks it is known.
+func normalizeMealSlot(value string) (string, error) {
+	slot := strings.ToLower(strings.TrimSpace(value))
+	if mealSlotIndex(slot) < 0 {
+		return "", errors.New("Slot must be one of " + strings.Join(models.MealSlots, ", "))
+	}
+	return slot, nil
+}
+
+func mealSlotIndex(slot string) int {
+	for i, known := range models.MealSlots {
+		if slot == known {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+)
+
+// @Summary Get notifications
+// @Description Get the current user's notifications, newest first
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.NotificationListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications [get]
+func GetNotifications(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", defaultNotificationPageSize)
+	if page < 1 || pageSize < 1 || pageSize > maxNotificationPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid pagination parameters",
+		})
+	}
+
+	unreadOnly := c.QueryBool("unread")
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("user_id = ?", userID)
+		if unreadOnly {
+			db = db.Where("read_at IS NULL")
+		}
+		return db
+	}
+
+	var total, unread int64
+	if result := database.DB.Model(&models.Notification{}).Scopes(scope).Count(&total); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get notifications",
+		})
+	}
+	if result := database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get notifications",
+		})
+	}
+
+	notifications := []models.Notification{}
+	result := database.DB.Scopes(scope).
+		Order("created_at DESC, id DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&notifications)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get notifications",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.NotificationListResponse{
+		Items:    notifications,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Unread:   unread,
+	})
+}
+
+// @Summary Mark notification read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} models.Notification
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications/{id}/read [put]
+func MarkNotificationRead(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var notification models.Notification
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&notification); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Notification not found",
+		})
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if result := database.DB.Model(&notification).Update("read_at", now); result.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update notification",
+			})
+		}
+		notification.ReadAt = &now
+	}
+
+	return c.Status(fiber.StatusOK).JSON(notification)
+}
+
+// @Summary Mark all notifications read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /notifications/read-all [put]
+func MarkAllNotificationsRead(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	result := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update notifications",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Notifications marked as read",
+		"updated": result.RowsAffected,
+	})
+}
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"foodapp/utils"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUseUpLimit = 10
+	maxUseUpLimit     = 50
+)
+
+// @Summary Get pantry
+// @Description Get the current user's pantry, soonest expiring first
+// @Tags pantry
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PantryItemResponse
+// @Failure 500 {object} map[string]string
+// @Router /pantry [get]
+func GetPantry(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var items []models.PantryItem
+	if result := database.DB.Where("user_id = ?", userID).Order("expires_on IS NULL, expires_on, id").Find(&items); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get pantry",
+		})
+	}
+
+	response := []models.PantryItemResponse{}
+	for _, item := range items {
+		response = append(response, convertPantryItemToResponse(item))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Add pantry item
+// @Description Add an amount of an ingredient to the current user's pantry
+// @Tags pantry
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.PantryItemRequest true "Pantry item"
+// @Success 201 {object} models.PantryItemResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pantry [post]
+func AddPantryItem(c *fiber.Ctx) error {
+	var req models.PantryItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.IngredientID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ingredient is required",
+		})
+	}
+
+	item := models.PantryItem{UserID: c.Locals("userID").(uint)}
+	return savePantryItem(c, item, req, fiber.StatusCreated)
+}
+
+// @Summary Update pantry item
+// @Description Change the quantity, unit or expiry date of a pantry item
+// @Tags pantry
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Pantry item ID"
+// @Param request body models.PantryItemRequest true "Pantry item"
+// @Success 200 {object} models.PantryItemResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pantry/{id} [put]
+func UpdatePantryItem(c *fiber.Ctx) error {
+	item, ok, err := findPantryItemFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.PantryItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	return savePantryItem(c, item, req, fiber.StatusOK)
+}
+
+// @Summary Delete pantry item
+// @Description Remove an item from the current user's pantry
+// @Tags pantry
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Pantry item ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pantry/{id} [delete]
+func DeletePantryItem(c *fiber.Ctx) error {
+	item, ok, err := findPantryItemFromParam(c)
+	if !ok {
+		return err
+	}
+
+	if result := database.DB.Delete(&item); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete pantry item",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Pantry item deleted successfully",
+	})
+}
+
+// @Summary Get use-up suggestions
+// @Description Rank dishes by how many of the current user's soon-to-expire pantry ingredients they use
+// @Tags dishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param days query int false "Days ahead an item counts as expiring (default 3)"
+// @Param limit query int false "Maximum number of dishes (default 10, max 50)"
+// @Param profile query string false "Dietary profile handling: flag (default), hide or off"
+// @Success 200 {array} models.UseUpSuggestion
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/use-up [get]
+func GetUseUpDishes(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	days := c.QueryInt("days", models.DefaultExpiryWindowDays)
+	limit := c.QueryInt("limit", defaultUseUpLimit)
+	if days < 0 || limit < 1 || limit > maxUseUpLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid days or limit",
+		})
+	}
+
+	profileMode, err := parseProfileMode(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile mode",
+		})
+	}
+
+	start := today()
+	var items []models.PantryItem
+	result := database.DB.Where("user_id = ? AND expires_on >= ? AND expires_on <= ?", userID, start, start.AddDate(0, 0, days)).
+		Order("expires_on, id").
+		Find(&items)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get pantry",
+		})
+	}
+
+	suggestions := []models.UseUpSuggestion{}
+	if len(items) == 0 {
+		return c.Status(fiber.StatusOK).JSON(suggestions)
+	}
+
+	ingredientIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		ingredientIDs = append(ingredientIDs, item.IngredientID)
+	}
+
+	var matches []struct {
+		DishID       uint
+		IngredientID uint
+	}
+	result = database.DB.Model(&models.DishIngredient{}).
+		Distinct("dish_id", "ingredient_id").
+		Where("ingredient_id IN ?", ingredientIDs).
+		Order("dish_id").
+		Scan(&matches)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get dishes",
+		})
+	}
+
+	// uses holds the expiring ingredients each dish calls for, as the dish
+	// rows name them; loadDishWithIngredients drops rows whose ingredient is
+	// gone.
+	var dishIDs []uint
+	uses := make(map[uint]map[uint]bool)
+	for _, match := range matches {
+		if uses[match.DishID] == nil {
+			uses[match.DishID] = make(map[uint]bool)
+			dishIDs = append(dishIDs, match.DishID)
+		}
+		uses[match.DishID][match.IngredientID] = true
+	}
+
+	var dishes []models.DishWithIngredients
+	expiring := make(map[uint][]models.PantryItemResponse)
+	for _, dishID := range dishIDs {
+		for _, item := range items {
+			if uses[dishID][item.IngredientID] {
+				expiring[dishID] = append(expiring[dishID], convertPantryItemToResponse(item))
+			}
+		}
+
+		var dish models.Dish
+		if database.DB.Limit(1).Find(&dish, dishID).RowsAffected == 0 {
+			continue
+		}
+		dishes = append(dishes, loadDishWithIngredients(dish))
+	}
+
+	// Most expiring ingredients first, then the dish using the item that
+	// expires soonest.
+	sort.SliceStable(dishes, func(i, j int) bool {
+		a, b := dishes[i].Dish.ID, dishes[j].Dish.ID
+		if len(uses[a]) != len(uses[b]) {
+			return len(uses[a]) > len(uses[b])
+		}
+		if expiring[a][0].ExpiresOn != expiring[b][0].ExpiresOn {
+			return expiring[a][0].ExpiresOn < expiring[b][0].ExpiresOn
+		}
+		return strings.ToLower(dishes[i].Dish.Name) < strings.ToLower(dishes[j].Dish.Name)
+	})
+
+	dishes = applyDietaryProfile(c, dishes, profileMode)
+	dishes = applyDishRatings(dishes)
+
+	for _, dish := range dishes {
+		if len(suggestions) == limit {
+			break
+		}
+		suggestions = append(suggestions, models.UseUpSuggestion{
+			DishWithIngredients: dish,
+			Expiring:            expiring[dish.Dish.ID],
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(suggestions)
+}
+
+// savePantryItem applies req to item, saves it and writes the response with
+// status. A zero IngredientID in req keeps the item's ingredient.
+func savePantryItem(c *fiber.Ctx, item models.PantryItem, req models.PantryItemRequest, status int) error {
+	if req.Quantity <= 0 || math.IsInf(req.Quantity, 0) || math.IsNaN(req.Quantity) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Quantity must be positive",
+		})
+	}
+
+	var expiresOn *time.Time
+	if req.ExpiresOn != "" {
+		date, err := time.Parse(models.PantryDateLayout, req.ExpiresOn)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid expiry date, use YYYY-MM-DD",
+			})
+		}
+		expiresOn = &date
+	}
+
+	if req.IngredientID != 0 {
+		var ingredient models.Ingredient
+		if result := database.DB.First(&ingredient, req.IngredientID); result.Error != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Ingredient not found",
+			})
+		}
+		item.IngredientID = ingredient.ID
+	}
+
+	item.Quantity = req.Quantity
+	item.Unit = strings.TrimSpace(req.Unit)
+	item.ExpiresOn = expiresOn
+
+	if result := database.DB.Save(&item); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save pantry item",
+		})
+	}
+
+	return c.Status(status).JSON(convertPantryItemToResponse(item))
+}
+
+func findPantryItemFromParam(c *fiber.Ctx) (item models.PantryItem, ok bool, err error) {
+	itemID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return item, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid pantry item ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if result := database.DB.Where("id = ? AND user_id = ?", itemID, userID).First(&item); result.Error != nil {
+		return item, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pantry item not found",
+		})
+	}
+
+	return item, true, nil
+}
+
+func convertPantryItemToResponse(item models.PantryItem) models.PantryItemResponse {
+	response := models.PantryItemResponse{
+		ID:           item.ID,
+		IngredientID: item.IngredientID,
+		Quantity:     item.Quantity,
+		Unit:         item.Unit,
+	}
+
+	var ingredient models.Ingredient
+	if database.DB.Select("id", "name").Limit(1).Find(&ingredient, item.IngredientID).RowsAffected > 0 {
+		response.IngredientName = ingredient.Name
+	}
+
+	if item.ExpiresOn != nil {
+		response.ExpiresOn = item.ExpiresOn.Format(models.PantryDateLayout)
+		response.Expired = item.ExpiresOn.Before(today())
+	}
+
+	return response
+}
+
+// today is the start of the current day, the cut-off for expired items.
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// pantryStock is a user's pantry loaded for working out how much of some
+// ingredients it can provide. take uses items up in memory; save writes the
+// result back.
+type pantryStock struct {
+	items   map[uint][]models.PantryItem
+	touched map[uint]bool
+}
+
+// loadPantryStock loads the user's items of the given ingredients, soonest
+// expiring first. Expired items are left out unless includeExpired is set.
+func loadPantryStock(userID uint, ingredientIDs []uint, includeExpired bool) (pantryStock, error) {
+	stock := pantryStock{items: make(map[uint][]models.PantryItem), touched: make(map[uint]bool)}
+
+	query := database.DB.Where("user_id = ? AND ingredient_id IN ?", userID, ingredientIDs)
+	if !includeExpired {
+		query = query.Where("expires_on IS NULL OR expires_on >= ?", today())
+	}
+
+	var items []models.PantryItem
+	if err := query.Order("expires_on IS NULL, expires_on, id").Find(&items).Error; err != nil {
+		return stock, err
+	}
+	for _, item := range items {
+		stock.items[item.IngredientID] = append(stock.items[item.IngredientID], item)
+	}
+	return stock, nil
+}
+
+// take uses up to quantity of an ingredient, given in unit, from the items
+// whose unit converts to it, and returns how much was covered in unit.
+func (s pantryStock) take(ingredientID uint, quantity float64, unit string) float64 {
+	remaining := quantity
+	items := s.items[ingredientID]
+	for i := range items {
+		if remaining <= 0 {
+			break
+		}
+
+		needed, ok := utils.ConvertQuantity(remaining, unit, items[i].Unit)
+		if !ok || items[i].Quantity <= 0 {
+			continue
+		}
+
+		used := math.Min(needed, items[i].Quantity)
+		items[i].Quantity = utils.RoundTo(items[i].Quantity-used, 2)
+		s.touched[ingredientID] = true
+
+		usedInUnit, _ := utils.ConvertQuantity(used, items[i].Unit, unit)
+		remaining -= usedInUnit
+	}
+
+	covered := quantity - math.Max(remaining, 0)
+	return utils.RoundTo(covered, 2)
+}
+
+// save writes the items take changed back, deleting the ones used up.
+func (s pantryStock) save(tx *gorm.DB) error {
+	for ingredientID := range s.touched {
+		for _, item := range s.items[ingredientID] {
+			if item.Quantity <= 0 {
+				if err := tx.Delete(&item).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&item).Update("quantity", item.Quantity).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
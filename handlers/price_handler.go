@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"foodapp/database"
+	"foodapp/models"
+	"foodapp/utils"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get ingredient prices
+// @Description Get the known prices of an ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Param id path int true "Ingredient ID"
+// @Success 200 {array} models.IngredientPrice
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/prices [get]
+func GetIngredientPrices(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	prices := []models.IngredientPrice{}
+	if result := database.DB.Where("ingredient_id = ?", ingredientID).Order("currency, store, id").Find(&prices); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get prices",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(prices)
+}
+
+// @Summary Set ingredient price
+// @Description Set what one unit of an ingredient costs, optionally at a given store. Replaces the existing price for the same currency and store
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param request body models.IngredientPriceRequest true "Price"
+// @Success 201 {object} models.IngredientPrice
+// @Success 200 {object} models.IngredientPrice
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/prices [post]
+func SetIngredientPrice(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var req models.IngredientPriceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Price <= 0 || math.IsInf(req.Price, 0) || math.IsNaN(req.Price) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Price must be positive",
+		})
+	}
+
+	currency := models.DefaultCurrency
+	if req.Currency != "" {
+		if currency, err = normalizeCurrency(req.Currency); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	store := strings.TrimSpace(req.Store)
+
+	var price models.IngredientPrice
+	found := database.DB.Where("ingredient_id = ? AND currency = ? AND LOWER(store) = LOWER(?)", ingredient.ID, currency, store).Limit(1).Find(&price)
+	if found.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to set price",
+		})
+	}
+
+	status := fiber.StatusOK
+	if found.RowsAffected == 0 {
+		status = fiber.StatusCreated
+		price = models.IngredientPrice{IngredientID: ingredient.ID, Currency: currency}
+	}
+	price.Price = req.Price
+	price.Unit = strings.TrimSpace(req.Unit)
+	price.Store = store
+
+	if result := database.DB.Save(&price); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to set price",
+		})
+	}
+
+	return c.Status(status).JSON(price)
+}
+
+// @Summary Delete ingredient price
+// @Description Remove a price from an ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param price_id path int true "Price ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/prices/{price_id} [delete]
+func DeleteIngredientPrice(c *fiber.Ctx) error {
+	result := database.DB.Where("id = ? AND ingredient_id = ?", c.Params("price_id"), c.Params("id")).Delete(&models.IngredientPrice{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete price",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Price not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Price deleted successfully",
+	})
+}
+
+// normalizeCurrency upper-cases a three letter ISO 4217 currency code.
+func normalizeCurrency(value string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(value))
+	if len(currency) != 3 {
+		return "", errors.New("Invalid currency")
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return "", errors.New("Invalid currency")
+		}
+	}
+	return currency, nil
+}
+
+// parseCurrencyQuery reads the optional ?currency parameter.
+func parseCurrencyQuery(c *fiber.Ctx) (string, error) {
+	if c.Query("currency") == "" {
+		return models.DefaultCurrency, nil
+	}
+	return normalizeCurrency(c.Query("currency"))
+}
+
+// ingredientPrices holds the prices of some ingredients in one currency.
+type ingredientPrices map[uint][]models.IngredientPrice
+
+func loadIngredientPrices(ingredientIDs []uint, currency string) ingredientPrices {
+	var rows []models.IngredientPrice
+	database.DB.Where("ingredient_id IN ? AND currency = ?", ingredientIDs, currency).Order("updated_at DESC, id DESC").Find(&rows)
+
+	prices := make(ingredientPrices)
+	for _, price := range rows {
+		prices[price.IngredientID] = append(prices[price.IngredientID], price)
+	}
+	return prices
+}
+
+// cost prices quantity of an ingredient. It prefers a price at store, then
+// one without a store, then any other store's, newest first, skipping prices
+// whose unit the quantity can't be converted to.
+func (p ingredientPrices) cost(ingredientID uint, quantity float64, unit, store string) (float64, bool) {
+	for _, tier := range []func(models.IngredientPrice) bool{
+		func(price models.IngredientPrice) bool { return store != "" && strings.EqualFold(price.Store, store) },
+		func(price models.IngredientPrice) bool { return price.Store == "" },
+		func(price models.IngredientPrice) bool { return true },
+	} {
+		for _, price := range p[ingredientID] {
+			if !tier(price) {
+				continue
+			}
+			if converted, ok := utils.ConvertQuantity(quantity, unit, price.Unit); ok {
+				return converted * price.Price, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// estimateCartCost prices every cart line at store and fills in their
+// estimated cost.
+func estimateCartCost(lines []models.CartResponse, currency, store string) models.CostEstimate {
+	ingredientIDs := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ingredientIDs = append(ingredientIDs, line.Ingredient.ID)
+	}
+	prices := loadIngredientPrices(ingredientIDs, currency)
+
+	estimate := models.CostEstimate{Currency: currency}
+	for i, line := range lines {
+		cost, ok := prices.cost(line.Ingredient.ID, float64(line.Quantity), line.Unit, store)
+		if !ok {
+			estimate.Unpriced = append(estimate.Unpriced, line.Ingredient.Name)
+			continue
+		}
+		cost = utils.RoundTo(cost, 2)
+		lines[i].EstimatedCost = &cost
+		estimate.Total += cost
+	}
+	estimate.Total = utils.RoundTo(estimate.Total, 2)
+	return estimate
+}
+
+// applyDishCosts attaches the estimated cost to every dish in the list, for
+// the servings each dish is shown with. Dishes without any priced ingredient
+// get no cost; dishes with only some priced get an incomplete one.
+func applyDishCosts(dishes []models.DishWithIngredients, currency string) []models.DishWithIngredients {
+	var ingredientIDs []uint
+	for _, dish := range dishes {
+		for _, ingredient := range dish.Ingredients {
+			ingredientIDs = append(ingredientIDs, ingredient.ID)
+		}
+	}
+	prices := loadIngredientPrices(ingredientIDs, currency)
+
+	for i, dish := range dishes {
+		cost := models.DishCost{CostEstimate: models.CostEstimate{Currency: currency}}
+		priced := false
+		for _, ingredient := range dish.Ingredients {
+			amount, ok := prices.cost(ingredient.ID, ingredient.Quantity, ingredient.Unit, "")
+			if !ok {
+				cost.Unpriced = append(cost.Unpriced, ingredient.Name)
+				continue
+			}
+			cost.Total += amount
+			priced = true
+		}
+		if !priced {
+			continue
+		}
+
+		servings := dish.Servings
+		if servings <= 0 {
+			servings = dishBaseServings(dish.Dish)
+		}
+		cost.PerServing = utils.RoundTo(cost.Total/float64(servings), 2)
+		cost.Total = utils.RoundTo(cost.Total, 2)
+		cost.Complete = len(cost.Unpriced) == 0
+		dishes[i].Cost = &cost
+	}
+	return dishes
+}
+
+// parseMaxCostQuery reads the optional ?max_cost per-serving limit; 0 means
+// no limit.
+func parseMaxCostQuery(c *fiber.Ctx) (float64, error) {
+	raw := c.Query("max_cost")
+	if raw == "" {
+		return 0, nil
+	}
+
+	maxCost, err := strconv.ParseFloat(raw, 64)
+	if err != nil || maxCost <= 0 {
+		return 0, errors.New("max_cost must be a positive number")
+	}
+	return maxCost, nil
+}
+
+// filterDishesByCost keeps the dishes whose estimated cost per serving is at
+// most maxCost. Dishes without a complete cost are dropped, as they may cost
+// more than it shows.
+func filterDishesByCost(dishes []models.DishWithIngredients, maxCost float64) []models.DishWithIngredients {
+	var filtered []models.DishWithIngredients
+	for _, dish := range dishes {
+		if dish.Cost != nil && dish.Cost.Complete && dish.Cost.PerServing <= maxCost {
+			filtered = append(filtered, dish)
+		}
+	}
+	return filtered
+}
+
+// sortDishesByCost orders dishes cheapest per serving first. Dishes with an
+// incomplete cost follow the fully priced ones, and dishes without a cost go
+// last.
+func sortDishesByCost(dishes []models.DishWithIngredients) {
+	rank := func(cost *models.DishCost) int {
+		switch {
+		case cost == nil:
+			return 2
+		case !cost.Complete:
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(dishes, func(i, j int) bool {
+		ri, rj := rank(dishes[i].Cost), rank(dishes[j].Cost)
+		if ri != rj || ri == 2 {
+			return ri < rj
+		}
+		return dishes[i].Cost.PerServing < dishes[j].Cost.PerServing
+	})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPurchasePageSize = 20
+	maxPurchasePageSize     = 100
+)
+
+// @Summary Check off cart lines
+// @Description Check or uncheck cart lines of the active cart while shopping, either the given lines or all of them
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CartCheckRequest true "Lines to check or uncheck"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/check [put]
+func CheckCartLines(c *fiber.Ctx) error {
+	var req models.CartCheckRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if !req.All && len(req.LineIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Give line_ids or set all",
+		})
+	}
+
+	owner := activeCart(c)
+	query := owner.lines(database.DB)
+	if !req.All {
+		query = query.Where("id IN ?", req.LineIDs)
+	}
+
+	var lines []models.Cart
+	if result := query.Order("id").Find(&lines); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch cart items",
+		})
+	}
+
+	if !req.All {
+		found := make(map[uint]bool, len(lines))
+		for _, line := range lines {
+			found[line.ID] = true
+		}
+		for _, id := range req.LineIDs {
+			if !found[id] {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Cart line not found",
+				})
+			}
+		}
+	}
+
+	var checkedAt *time.Time
+	if req.Checked {
+		now := time.Now()
+		checkedAt = &now
+	}
+
+	var changed []models.Cart
+	for _, line := range lines {
+		if line.Checked != req.Checked {
+			line.Checked = req.Checked
+			line.CheckedAt = checkedAt
+			changed = append(changed, line)
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range changed {
+			if err := tx.Model(&changed[i]).Select("checked", "checked_at").Updates(&changed[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update cart",
+		})
+	}
+
+	for _, line := range changed {
+		publishCartLine(owner, models.CartEventLineUpdated, line)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Cart updated successfully",
+		"updated": len(changed),
+	})
+}
+
+// @Summary Finish shopping trip
+// @Description Move the checked lines of the active cart into the purchase history; unchecked lines stay in the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 201 {object} models.Purchase
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/finish-trip [post]
+func FinishTrip(c *fiber.Ctx) error {
+	owner := activeCart(c)
+
+	var lines []models.Cart
+	if result := owner.lines(database.DB).Where("checked = ?", true).Order("id").Find(&lines); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch cart items",
+		})
+	}
+
+	if len(lines) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No checked lines in cart",
+		})
+	}
+
+	ingredientIDs := make([]uint, 0, len(lines))
+	lineIDs := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ingredientIDs = append(ingredientIDs, line.IngredientID)
+		lineIDs = append(lineIDs, line.ID)
+	}
+
+	var ingredients []models.Ingredient
+	database.DB.Select("id", "name").Where("id IN ?", ingredientIDs).Find(&ingredients)
+	names := make(map[uint]string, len(ingredients))
+	for _, ingredient := range ingredients {
+		names[ingredient.ID] = ingredient.Name
+	}
+
+	purchase := models.Purchase{
+		UserID:      owner.UserID,
+		HouseholdID: owner.HouseholdID,
+		FinishedAt:  time.Now(),
+	}
+	for _, line := range lines {
+		purchase.Items = append(purchase.Items, models.PurchaseItem{
+			IngredientID:   line.IngredientID,
+			IngredientName: names[line.IngredientID],
+			Quantity:       line.Quantity,
+			Unit:           line.Unit,
+			AddedBy:        line.AddedBy,
+			CheckedAt:      line.CheckedAt,
+		})
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&purchase).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cart_id IN ?", lineIDs).Delete(&models.CartDishContribution{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", lineIDs).Delete(&models.Cart{}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to finish trip",
+		})
+	}
+
+	for _, line := range lines {
+		line.Quantity = 0
+		publishCartLine(owner, models.CartEventLineRemoved, line)
+	}
+	publishCartEvent(owner, models.CartEvent{Type: models.CartEventTripFinished})
+
+	return c.Status(fiber.StatusCreated).JSON(purchase)
+}
+
+// @Summary Get purchase history
+// @Description Get a page of finished shopping trips of the current user and of the households they belong to, newest first
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Page size (max 100)"
+// @Success 200 {object} models.PurchaseListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/history [get]
+func GetPurchaseHistory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", defaultPurchasePageSize)
+	if page < 1 || pageSize < 1 || pageSize > maxPurchasePageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid pagination parameters",
+		})
+	}
+
+	households := database.DB.Model(&models.HouseholdMember{}).Select("household_id").Where("user_id = ?", userID)
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("(user_id = ? AND household_id IS NULL) OR household_id IN (?)", userID, households)
+	}
+
+	var total int64
+	if result := database.DB.Model(&models.Purchase{}).Scopes(scope).Count(&total); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get purchase history",
+		})
+	}
+
+	purchases := []models.Purchase{}
+	result := database.DB.Scopes(scope).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("finished_at DESC, id DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&purchases)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get purchase history",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.PurchaseListResponse{
+		Items:    purchases,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+	"foodapp/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get dish reviews
+// @Description Get the visible reviews of a dish, newest first
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Dish ID"
+// @Success 200 {array} models.DishReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/reviews [get]
+func GetDishReviews(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var reviews []models.DishReview
+	if result := database.DB.Where("dish_id = ? AND hidden = ?", dish.ID, false).Order("created_at DESC, id DESC").Find(&reviews); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch reviews",
+		})
+	}
+
+	response := []models.DishReviewResponse{}
+	for _, review := range reviews {
+		response = append(response, convertReviewToResponse(review))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Review a dish
+// @Description Rate (1-5) and review a dish the current user has cooked; posting again replaces the earlier review
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Param review body models.DishReviewRequest true "Review"
+// @Success 201 {object} models.DishReviewResponse
+// @Success 200 {object} models.DishReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/reviews [post]
+func AddDishReview(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.DishReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Rating < 1 || req.Rating > 5 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rating must be between 1 and 5",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	var cooked int64
+	database.DB.Model(&models.Statistics{}).Where("user_id = ? AND dish_id = ?", userID, dish.ID).Count(&cooked)
+	if cooked == 0 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only dishes you have cooked can be reviewed",
+		})
+	}
+
+	var review models.DishReview
+	result := database.DB.Where("dish_id = ? AND user_id = ?", dish.ID, userID).First(&review)
+	status := fiber.StatusOK
+	if result.RowsAffected == 0 {
+		review = models.DishReview{
+			DishID: dish.ID,
+			UserID: userID,
+		}
+		status = fiber.StatusCreated
+	}
+
+	review.Rating = req.Rating
+	review.Comment = strings.TrimSpace(req.Comment)
+
+	if result := database.DB.Save(&review); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save review",
+		})
+	}
+
+	return c.Status(status).JSON(convertReviewToResponse(review))
+}
+
+// @Summary Delete own review
+// @Description Delete the current user's review of a dish
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/reviews [delete]
+func DeleteDishReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	result := database.DB.Where("dish_id = ? AND user_id = ?", c.Params("id"), userID).Delete(&models.DishReview{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete review",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Review not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Review deleted successfully",
+	})
+}
+
+// @Summary Hide a review
+// @Description Hide a review from listings and averages (editors only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param review_id path int true "Review ID"
+// @Param request body models.ReviewModerationRequest false "Moderation reason"
+// @Success 200 {object} models.DishReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /reviews/{review_id}/hide [put]
+func HideReview(c *fiber.Ctx) error {
+	return setReviewHidden(c, true)
+}
+
+// @Summary Unhide a review
+// @Description Make a hidden review visible again (editors only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param review_id path int true "Review ID"
+// @Success 200 {object} models.DishReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /reviews/{review_id}/unhide [put]
+func UnhideReview(c *fiber.Ctx) error {
+	return setReviewHidden(c, false)
+}
+
+// @Summary Get hidden reviews
+// @Description Get all hidden reviews for moderation (editors only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.DishReview
+// @Failure 500 {object} map[string]string
+// @Router /reviews/hidden [get]
+func GetHiddenReviews(c *fiber.Ctx) error {
+	reviews := []models.DishReview{}
+	if result := database.DB.Where("hidden = ?", true).Order("updated_at DESC").Find(&reviews); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch reviews",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(reviews)
+}
+
+func setReviewHidden(c *fiber.Ctx, hidden bool) error {
+	reviewID, err := strconv.Atoi(c.Params("review_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID",
+		})
+	}
+
+	var req models.ReviewModerationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	var review models.DishReview
+	if result := database.DB.First(&review, reviewID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Review not found",
+		})
+	}
+
+	review.Hidden = hidden
+	review.HiddenBy = 0
+	review.HiddenReason = ""
+	if hidden {
+		review.HiddenBy = c.Locals("userID").(uint)
+		review.HiddenReason = req.Reason
+	}
+
+	if result := database.DB.Save(&review); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update review",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(convertReviewToResponse(review))
+}
+
+func convertReviewToResponse(review models.DishReview) models.DishReviewResponse {
+	var user models.User
+	database.DB.Select("id", "user_name").First(&user, review.UserID)
+
+	return models.DishReviewResponse{
+		ID:        review.ID,
+		DishID:    review.DishID,
+		UserID:    review.UserID,
+		UserName:  user.UserName,
+		Rating:    review.Rating,
+		Comment:   review.Comment,
+		Hidden:    review.Hidden,
+		CreatedAt: review.CreatedAt,
+		UpdatedAt: review.UpdatedAt,
+	}
+}
+
+// visibleRatings is the per-dish average and count of visible reviews.
+func visibleRatings() *gorm.DB {
+	return database.DB.Model(&models.DishReview{}).
+		Select("dish_id, AVG(rating) AS average, COUNT(*) AS count").
+		Where("hidden = ?", false).
+		Group("dish_id")
+}
+
+func dishRatings(dishIDs []uint) map[uint]models.RatingSummary {
+	ratings := make(map[uint]models.RatingSummary)
+	if len(dishIDs) == 0 {
+		return ratings
+	}
+
+	var rows []struct {
+		DishID  uint
+		Average float64
+		Count   int64
+	}
+	visibleRatings().Where("dish_id IN ?", dishIDs).Scan(&rows)
+
+	for _, row := range rows {
+		ratings[row.DishID] = models.RatingSummary{
+			Average: utils.RoundTo(row.Average, 2),
+			Count:   row.Count,
+		}
+	}
+	return ratings
+}
+
+// applyDishRatings attaches the rating summary to every dish in the list.
+func applyDishRatings(dishes []models.DishWithIngredients) []models.DishWithIngredients {
+	dishIDs := make([]uint, 0, len(dishes))
+	for _, dish := range dishes {
+		dishIDs = append(dishIDs, dish.Dish.ID)
+	}
+	ratings := dishRatings(dishIDs)
+
+	for i, dish := range dishes {
+		rating := ratings[dish.Dish.ID]
+		dishes[i].Rating = &rating
+	}
+	return dishes
+}
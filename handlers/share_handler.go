@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"foodapp/database"
+	"foodapp/models"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Create share link
+// @Description Create a revocable public link to one of the user's collections or to their cart
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.ShareRequest true "What to share and how"
+// @Success 201 {object} models.ShareLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shares [post]
+func CreateShare(c *fiber.Ctx) error {
+	var req models.ShareRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	if req.Permission == "" {
+		req.Permission = models.SharePermissionRead
+	}
+	if req.Permission != models.SharePermissionRead && req.Permission != models.SharePermissionEdit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Permission must be read or edit",
+		})
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Expiry must be in the future",
+		})
+	}
+
+	switch req.ResourceType {
+	case models.ShareResourceCollection:
+		var count int64
+		database.DB.Model(&models.FavoriteCollection{}).Where("id = ? AND user_id = ?", req.ResourceID, userID).Count(&count)
+		if count == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Collection not found",
+			})
+		}
+	case models.ShareResourceCart:
+		req.ResourceID = 0
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Resource type must be collection or cart",
+		})
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create share",
+		})
+	}
+
+	share := models.ShareLink{
+		UserID:       userID,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Token:        token,
+		Permission:   req.Permission,
+		ExpiresAt:    req.ExpiresAt,
+	}
+
+	if result := database.DB.Create(&share); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create share",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(share)
+}
+
+// @Summary Get share links
+// @Description Get the share links created by the current user, newest first
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.ShareLink
+// @Failure 500 {object} map[string]string
+// @Router /shares [get]
+func GetShares(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	shares := []models.ShareLink{}
+	if result := database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&shares); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch shares",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(shares)
+}
+
+// @Summary Revoke share link
+// @Description Revoke a share link so its token stops working
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Share ID"
+// @Success 200 {object} models.ShareLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shares/{id} [delete]
+func RevokeShare(c *fiber.Ctx) error {
+	share, ok, err := findShareFromParam(c)
+	if !ok {
+		return err
+	}
+
+	if share.RevokedAt == nil {
+		now := time.Now()
+		share.RevokedAt = &now
+		if result := database.DB.Save(&share); result.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke share",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(share)
+}
+
+// @Summary Get share accesses
+// @Description Get the audit log of a share link, newest first
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Share ID"
+// @Success 200 {array} models.ShareAccess
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shares/{id}/accesses [get]
+func GetShareAccesses(c *fiber.Ctx) error {
+	share, ok, err := findShareFromParam(c)
+	if !ok {
+		return err
+	}
+
+	accesses := []models.ShareAccess{}
+	if result := database.DB.Where("share_id = ?", share.ID).Order("created_at DESC, id DESC").Find(&accesses); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch accesses",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(accesses)
+}
+
+// @Summary Open share link
+// @Description Get the collection or cart behind a share token; no sign-in needed
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} models.SharedResource
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token} [get]
+func GetSharedResource(c *fiber.Ctx) error {
+	share, ok, err := findShareFromToken(c, "", models.SharePermissionRead)
+	if !ok {
+		return err
+	}
+
+	resource := models.SharedResource{
+		ResourceType: share.ResourceType,
+		Permission:   share.Permission,
+		ExpiresAt:    share.ExpiresAt,
+	}
+
+	switch share.ResourceType {
+	case models.ShareResourceCollection:
+		var collection models.FavoriteCollection
+		if result := database.DB.Where("id = ? AND user_id = ?", share.ResourceID, share.UserID).First(&collection); result.Error != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Collection not found",
+			})
+		}
+		withDishes := loadCollectionWithDishes(c, collection)
+		resource.Collection = &withDishes
+	case models.ShareResourceCart:
+		cart, err := loadCart(personalCart(share.UserID))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch cart items",
+			})
+		}
+		resource.Cart = cart
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resource)
+}
+
+// @Summary Add ingredient through share link
+// @Description Add an ingredient to a shared cart; needs an edit link
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Param request body models.CartRequest true "Ingredient and quantity"
+// @Success 201 {object} map[string]interface{}
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token}/cart [post]
+func AddSharedCartIngredient(c *fiber.Ctx) error {
+	share, ok, err := findShareFromToken(c, models.ShareResourceCart, models.SharePermissionEdit)
+	if !ok {
+		return err
+	}
+
+	var req models.CartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Quantity <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Quantity must be positive",
+		})
+	}
+
+	req.UserID = share.UserID
+	return addIngredientToCart(c, personalCart(share.UserID), req)
+}
+
+// @Summary Remove ingredient through share link
+// @Description Remove an ingredient from a shared cart; needs an edit link
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Param ingredient_id path int true "Ingredient ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token}/cart/{ingredient_id} [delete]
+func RemoveSharedCartIngredient(c *fiber.Ctx) error {
+	share, ok, err := findShareFromToken(c, models.ShareResourceCart, models.SharePermissionEdit)
+	if !ok {
+		return err
+	}
+
+	ingredientID, convErr := strconv.Atoi(c.Params("ingredient_id"))
+	if convErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	return removeIngredientFromCart(c, personalCart(share.UserID), uint(ingredientID))
+}
+
+// @Summary Add dish through share link
+// @Description Add a dish to a shared collection; needs an edit link
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Param request body models.CollectionDishRequest true "Dish and note"
+// @Success 201 {object} models.FavoriteDish
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token}/dishes [post]
+func AddSharedCollectionDish(c *fiber.Ctx) error {
+	share, ok, err := findShareFromToken(c, models.ShareResourceCollection, models.SharePermissionEdit)
+	if !ok {
+		return err
+	}
+
+	var collection models.FavoriteCollection
+	if result := database.DB.Where("id = ? AND user_id = ?", share.ResourceID, share.UserID).First(&collection); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Collection not found",
+		})
+	}
+
+	var req models.CollectionDishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	return addDishToCollection(c, collection, req)
+}
+
+// @Summary Remove dish through share link
+// @Description Remove a dish from a shared collection; needs an edit link
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Param dish_id path int true "Dish ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token}/dishes/{dish_id} [delete]
+func RemoveSharedCollectionDish(c *fiber.Ctx) error {
+	share, ok, err := findShareFromToken(c, models.ShareResourceCollection, models.SharePermissionEdit)
+	if !ok {
+		return err
+	}
+
+	var collection models.FavoriteCollection
+	if result := database.DB.Where("id = ? AND user_id = ?", share.ResourceID, share.UserID).First(&collection); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Collection not found",
+		})
+	}
+
+	return removeDishFromCollection(c, collection, c.Params("dish_id"))
+}
+
+// findShareFromParam loads the :id share link of the current user, writing
+// the 400/404 response itself when it cannot.
+func findShareFromParam(c *fiber.Ctx) (share models.ShareLink, ok bool, err error) {
+	shareID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return share, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid share ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if result := database.DB.Where("id = ? AND user_id = ?", shareID, userID).First(&share); result.Error != nil {
+		return share, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Share not found",
+		})
+	}
+
+	return share, true, nil
+}
+
+// findShareFromToken resolves the :token share link, checks it is live, of
+// the given resource type (any when empty) and grants permission, and records
+// the access. It writes the error response itself when the link is unusable.
+func findShareFromToken(c *fiber.Ctx, resourceType, permission string) (share models.ShareLink, ok bool, err error) {
+	result := database.DB.Where("token = ?", c.Params("token")).First(&share)
+	if result.Error != nil || (resourceType != "" && share.ResourceType != resourceType) {
+		return share, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Share not found",
+		})
+	}
+
+	if share.RevokedAt != nil || (share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now())) {
+		return share, false, c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Share link is no longer valid",
+		})
+	}
+
+	if permission == models.SharePermissionEdit && share.Permission != models.SharePermissionEdit {
+		return share, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Share link is read-only",
+		})
+	}
+
+	action := "view"
+	if permission == models.SharePermissionEdit {
+		action = "edit"
+	}
+
+	access := models.ShareAccess{
+		ShareID:   share.ID,
+		Action:    action,
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+	if userID, signedIn := c.Locals("userID").(uint); signedIn {
+		access.UserID = &userID
+	}
+	database.DB.Create(&access)
+
+	return share, true, nil
+}
+
+func newShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -2,13 +2,23 @@ package handlers
 
 import (
 	"encoding/base64"
+	"errors"
 	"foodapp/database"
 	"foodapp/models"
+	"foodapp/utils"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// maxDiaryRangeDays caps the nutrition diary range at about a year.
+const maxDiaryRangeDays = 366
+
+// sqliteDateTimeLayout is how SQLite's datetime() writes a UTC time.
+const sqliteDateTimeLayout = "2006-01-02 15:04:05"
+
 func GetStatistics(c *fiber.Ctx) error {
 	userIDStr := c.Params("user_id")
 	userID, err := strconv.Atoi(userIDStr)
@@ -99,15 +109,41 @@ func AddStatistics(c *fiber.Ctx) error {
 		CreatedAt: time.Now(),
 	}
 
-	if result := database.DB.Create(&statistics); result.Error != nil {
+	// Cooking the dish uses its ingredients up from the pantry, expired
+	// items included.
+	var dishIngredients []models.DishIngredient
+	database.DB.Where("dish_id = ?", dish.ID).Find(&dishIngredients)
+
+	pantry, err := loadPantryStock(userID, dishIngredientIDs(dishIngredients), true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch pantry",
+		})
+	}
+
+	used := []models.PantryUsage{}
+	for _, di := range dishIngredients {
+		if taken := pantry.take(di.IngredientID, di.Quantity, di.Unit); taken > 0 {
+			used = append(used, models.PantryUsage{IngredientID: di.IngredientID, Quantity: taken, Unit: di.Unit})
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&statistics).Error; err != nil {
+			return err
+		}
+		return pantry.save(tx)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to add shoto tam",
 		})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Ingredient added to dishes successfully",
-		"id":      statistics.ID,
+		"message":     "Ingredient added to dishes successfully",
+		"id":          statistics.ID,
+		"pantry_used": used,
 	})
 }
 
@@ -131,3 +167,171 @@ func RemoveStatistics(c *fiber.Ctx) error {
 	})
 
 }
+
+// @Summary Get nutrition diary
+// @Description Total the calories, proteins, fats, carbs and number of dishes eaten per day, week (from Monday) or month. Days begin at midnight in the user's time zone. Without from and to the diary covers the last 7 days, 4 weeks or 3 months
+// @Tags statistics
+// @Produce json
+// @Security ApiKeyAuth
+// @Param group query string false "day (default), week or month"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Success 200 {object} models.NutritionDiaryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /statistics/diary [get]
+func GetNutritionDiary(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	location := userLocation(userID)
+
+	group := strings.ToLower(strings.TrimSpace(c.Query("group", models.DiaryGroupDay)))
+	if group != models.DiaryGroupDay && group != models.DiaryGroupWeek && group != models.DiaryGroupMonth {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Group must be one of day, week, month",
+		})
+	}
+
+	from, to, err := parseDiaryRange(c.Query("from"), c.Query("to"), group, location)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	periods, bounds := diaryPeriods(from, to, group)
+
+	if err := sumIntake(userID, periods, bounds); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch statistics",
+		})
+	}
+
+	total := models.NutritionDiaryPeriod{
+		From: from.Format(models.MealPlanDateLayout),
+		To:   to.Format(models.MealPlanDateLayout),
+	}
+	for _, period := range periods {
+		total.Dishes += period.Dishes
+		total.Nutrition = addNutrition(total.Nutrition, period.Nutrition)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.NutritionDiaryResponse{
+		Timezone: location.String(),
+		Group:    group,
+		From:     total.From,
+		To:       total.To,
+		Periods:  periods,
+		Total:    total,
+	})
+}
+
+// sumIntake fills in the dishes and nutrition the user ate in each period,
+// bounds holding the instants the periods start and end at.
+func sumIntake(userID uint, periods []models.NutritionDiaryPeriod, bounds [][2]time.Time) error {
+	// Each period is a row of boundaries in UTC; datetime() brings the
+	// stored timestamps, whatever their offset, to UTC as well.
+	var values []string
+	var args []interface{}
+	for i, bound := range bounds {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, i, bound[0].UTC().Format(sqliteDateTimeLayout), bound[1].UTC().Format(sqliteDateTimeLayout))
+	}
+	args = append(args, userID)
+
+	var rows []struct {
+		Period   int
+		Dishes   int
+		Calories float64
+		Fats     float64
+		Carbs    float64
+		Proteins float64
+	}
+	query := "WITH periods(period, starts_at, ends_at) AS (VALUES " + strings.Join(values, ", ") + ") " +
+		"SELECT periods.period, COUNT(dishes.id) AS dishes, " +
+		"COALESCE(SUM(dishes.calories), 0) AS calories, COALESCE(SUM(dishes.fats), 0) AS fats, " +
+		"COALESCE(SUM(dishes.carbs), 0) AS carbs, COALESCE(SUM(dishes.proteins), 0) AS proteins " +
+		"FROM periods " +
+		"JOIN statistics ON datetime(statistics.created_at) >= periods.starts_at AND datetime(statistics.created_at) < periods.ends_at " +
+		"JOIN dishes ON dishes.id = statistics.dish_id " +
+		"WHERE statistics.user_id = ? " +
+		"GROUP BY periods.period"
+	if result := database.DB.Raw(query, args...).Scan(&rows); result.Error != nil {
+		return result.Error
+	}
+
+	for _, row := range rows {
+		periods[row.Period].Dishes = row.Dishes
+		periods[row.Period].Nutrition = models.Nutrition{
+			Calories: utils.RoundTo(row.Calories, 1),
+			Fats:     utils.RoundTo(row.Fats, 1),
+			Carbs:    utils.RoundTo(row.Carbs, 1),
+			Proteins: utils.RoundTo(row.Proteins, 1),
+		}
+	}
+	return nil
+}
+
+// parseDiaryRange reads the diary range as midnights in location, filling in
+// the defaults of group for whatever is missing.
+func parseDiaryRange(fromValue, toValue, group string, location *time.Location) (from, to time.Time, err error) {
+	to = localToday(location)
+	if toValue != "" {
+		if to, err = time.ParseInLocation(models.MealPlanDateLayout, strings.TrimSpace(toValue), location); err != nil {
+			return from, to, errors.New("Invalid to, use YYYY-MM-DD")
+		}
+	}
+
+	switch {
+	case fromValue != "":
+		if from, err = time.ParseInLocation(models.MealPlanDateLayout, strings.TrimSpace(fromValue), location); err != nil {
+			return from, to, errors.New("Invalid from, use YYYY-MM-DD")
+		}
+	case group == models.DiaryGroupWeek:
+		from = weekStart(to).AddDate(0, 0, -21)
+	case group == models.DiaryGroupMonth:
+		from = time.Date(to.Year(), to.Month()-2, 1, 0, 0, 0, 0, location)
+	default:
+		from = to.AddDate(0, 0, -6)
+	}
+
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+	if to.After(from.AddDate(0, 0, maxDiaryRangeDays-1)) {
+		return from, to, errors.New("Range must not exceed " + strconv.Itoa(maxDiaryRangeDays) + " days")
+	}
+	return from, to, nil
+}
+
+// diaryPeriods splits the days from to to into periods of group, the first
+// and last cut to the range. bounds holds the instants each period starts
+// and ends at; days are stepped with AddDate so they stay midnight-to-midnight
+// across daylight saving changes.
+func diaryPeriods(from, to time.Time, group string) ([]models.NutritionDiaryPeriod, [][2]time.Time) {
+	end := to.AddDate(0, 0, 1)
+
+	var periods []models.NutritionDiaryPeriod
+	var bounds [][2]time.Time
+	for start := from; start.Before(end); {
+		var next time.Time
+		switch group {
+		case models.DiaryGroupWeek:
+			next = weekStart(start).AddDate(0, 0, 7)
+		case models.DiaryGroupMonth:
+			next = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, start.Location())
+		default:
+			next = start.AddDate(0, 0, 1)
+		}
+		if next.After(end) {
+			next = end
+		}
+
+		periods = append(periods, models.NutritionDiaryPeriod{
+			From: start.Format(models.MealPlanDateLayout),
+			To:   next.AddDate(0, 0, -1).Format(models.MealPlanDateLayout),
+		})
+		bounds = append(bounds, [2]time.Time{start, next})
+		start = next
+	}
+	return periods, bounds
+}
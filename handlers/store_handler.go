@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"foodapp/database"
+	"foodapp/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get grocery sections
+// @Description Get the grocery sections ingredients can be filed under, in the default store order
+// @Tags stores
+// @Produce json
+// @Success 200 {array} string
+// @Router /stores/sections [get]
+func GetGrocerySections(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.GrocerySections)
+}
+
+// @Summary Get store profiles
+// @Description Get the current user's store profiles
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.StoreProfileResponse
+// @Failure 500 {object} map[string]string
+// @Router /stores [get]
+func GetStoreProfiles(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var stores []models.StoreProfile
+	if result := database.DB.Where("user_id = ?", userID).Order("name, id").Find(&stores); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get store profiles",
+		})
+	}
+
+	response := []models.StoreProfileResponse{}
+	for _, store := range stores {
+		response = append(response, convertStoreProfileToResponse(store))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Get store profile
+// @Description Get one of the current user's store profiles with its full section order
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Store profile ID"
+// @Success 200 {object} models.StoreProfileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /stores/{id} [get]
+func GetStoreProfile(c *fiber.Ctx) error {
+	store, ok, err := findStoreProfileFromParam(c)
+	if !ok {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(convertStoreProfileToResponse(store))
+}
+
+// @Summary Create store profile
+// @Description Create a store profile with the order the user walks its sections in
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.StoreProfileRequest true "Store profile"
+// @Success 201 {object} models.StoreProfileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stores [post]
+func CreateStoreProfile(c *fiber.Ctx) error {
+	var req models.StoreProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	store := models.StoreProfile{UserID: c.Locals("userID").(uint)}
+	return saveStoreProfile(c, store, req, fiber.StatusCreated)
+}
+
+// @Summary Update store profile
+// @Description Rename a store profile, replace its section order or make it the default
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Store profile ID"
+// @Param request body models.StoreProfileRequest true "Store profile"
+// @Success 200 {object} models.StoreProfileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stores/{id} [put]
+func UpdateStoreProfile(c *fiber.Ctx) error {
+	store, ok, err := findStoreProfileFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.StoreProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	return saveStoreProfile(c, store, req, fiber.StatusOK)
+}
+
+// @Summary Delete store profile
+// @Description Delete one of the current user's store profiles
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Store profile ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stores/{id} [delete]
+func DeleteStoreProfile(c *fiber.Ctx) error {
+	store, ok, err := findStoreProfileFromParam(c)
+	if !ok {
+		return err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("store_profile_id = ?", store.ID).Delete(&models.StoreSection{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&store).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete store profile",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Store profile deleted successfully",
+	})
+}
+
+// saveStoreProfile applies req to store, replaces its sections and writes the
+// response with status.
+func saveStoreProfile(c *fiber.Ctx, store models.StoreProfile, req models.StoreProfileRequest, status int) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	var sections []string
+	for _, value := range req.Sections {
+		section, err := normalizeSection(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if section != "" {
+			sections = append(sections, section)
+		}
+	}
+	sections = uniqueStrings(sections)
+
+	store.Name = name
+	store.IsDefault = req.IsDefault
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if store.IsDefault {
+			if err := tx.Model(&models.StoreProfile{}).Where("user_id = ? AND id <> ?", store.UserID, store.ID).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&store).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("store_profile_id = ?", store.ID).Delete(&models.StoreSection{}).Error; err != nil {
+			return err
+		}
+		for i, section := range sections {
+			row := models.StoreSection{StoreProfileID: store.ID, Section: section, Position: i + 1}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save store profile",
+		})
+	}
+
+	return c.Status(status).JSON(convertStoreProfileToResponse(store))
+}
+
+func findStoreProfileFromParam(c *fiber.Ctx) (store models.StoreProfile, ok bool, err error) {
+	storeID, convErr := strconv.Atoi(c.Params("id"))
+	if convErr != nil {
+		return store, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid store profile ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	if result := database.DB.Where("id = ? AND user_id = ?", storeID, userID).First(&store); result.Error != nil {
+		return store, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Store profile not found",
+		})
+	}
+
+	return store, true, nil
+}
+
+func convertStoreProfileToResponse(store models.StoreProfile) models.StoreProfileResponse {
+	return models.StoreProfileResponse{
+		ID:        store.ID,
+		Name:      store.Name,
+		IsDefault: store.IsDefault,
+		Sections:  storeSectionOrder(&store),
+		CreatedAt: store.CreatedAt,
+	}
+}
+
+// storeSectionOrder returns every grocery section in the order they are walked
+// in store: its own sections first, the rest in default order. A nil store
+// gives the default order.
+func storeSectionOrder(store *models.StoreProfile) []string {
+	var order []string
+	if store != nil {
+		var sections []models.StoreSection
+		database.DB.Where("store_profile_id = ?", store.ID).Order("position").Find(&sections)
+		for _, section := range sections {
+			order = append(order, section.Section)
+		}
+	}
+	return uniqueStrings(append(order, models.GrocerySections...))
+}
+
+// cartStore picks the store the cart is shopped at: the store profile given
+// in the query, else the user's default one. store is nil when the user has
+// no default. It writes the response when the query names an invalid store.
+func cartStore(c *fiber.Ctx) (store *models.StoreProfile, ok bool, err error) {
+	userID := c.Locals("userID").(uint)
+
+	var profile models.StoreProfile
+	if storeParam := c.Query("store"); storeParam != "" {
+		storeID, convErr := strconv.Atoi(storeParam)
+		if convErr != nil {
+			return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid store profile ID",
+			})
+		}
+		if result := database.DB.Where("id = ? AND user_id = ?", storeID, userID).First(&profile); result.Error != nil {
+			return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Store profile not found",
+			})
+		}
+		return &profile, true, nil
+	}
+
+	if database.DB.Where("user_id = ? AND is_default = ?", userID, true).Limit(1).Find(&profile).RowsAffected > 0 {
+		return &profile, true, nil
+	}
+	return nil, true, nil
+}
+
+// groupCartBySection groups cart lines by their ingredient's section, in the
+// given section order, with lines sorted by ingredient name inside a section.
+func groupCartBySection(lines []models.CartResponse, order []string) []models.CartSectionResponse {
+	bySection := make(map[string][]models.CartResponse)
+	for _, line := range lines {
+		section := line.Ingredient.Section
+		if section == "" {
+			section = models.GrocerySectionOther
+		}
+		bySection[section] = append(bySection[section], line)
+	}
+
+	groups := []models.CartSectionResponse{}
+	for _, section := range order {
+		sectionLines, found := bySection[section]
+		if !found {
+			continue
+		}
+		sort.SliceStable(sectionLines, func(i, j int) bool {
+			return strings.ToLower(sectionLines[i].Ingredient.Name) < strings.ToLower(sectionLines[j].Ingredient.Name)
+		})
+		groups = append(groups, models.CartSectionResponse{Section: section, Lines: sectionLines})
+	}
+	return groups
+}
+
+// normalizeSection lower-cases a grocery section and checks it is known.
+// An empty value stays empty.
+func normalizeSection(value string) (string, error) {
+	section := strings.ToLower(strings.TrimSpace(value))
+	if section == "" {
+		return "", nil
+	}
+	for _, known := range models.GrocerySections {
+		if section == known {
+			return section, nil
+		}
+	}
+	return "", errors.New("Unknown grocery section: " + value)
+}
@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"foodapp/database"
+	"foodapp/models"
+	"foodapp/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// @Summary Get ingredient substitutes
+// @Description Get the ingredients that can replace an ingredient, optionally for one dietary reason
+// @Tags substitutions
+// @Accept json
+// @Produce json
+// @Param id path int true "Ingredient ID"
+// @Param diet query string false "Dietary reason, e.g. vegan or milk"
+// @Success 200 {array} models.SubstitutionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/substitutes [get]
+func GetIngredientSubstitutes(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var ingredient models.Ingredient
+	if result := database.DB.First(&ingredient, ingredientID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	query := database.DB.Where("ingredient_id = ?", ingredient.ID)
+	if diet := normalizeDietaryReason(c.Query("diet")); diet != "" {
+		query = query.Where("dietary_reason = ?", diet)
+	}
+
+	var substitutions []models.IngredientSubstitution
+	if result := query.Order("id").Find(&substitutions); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch substitutes",
+		})
+	}
+
+	response := []models.SubstitutionResponse{}
+	for _, substitution := range substitutions {
+		response = append(response, convertSubstitutionToResponse(substitution))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Add ingredient substitute
+// @Description Declare that another ingredient can replace this one
+// @Tags substitutions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param request body models.SubstitutionRequest true "Substitution details"
+// @Success 201 {object} models.SubstitutionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/substitutes [post]
+func AddIngredientSubstitute(c *fiber.Ctx) error {
+	ingredientID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ingredient ID",
+		})
+	}
+
+	var req models.SubstitutionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Ratio == 0 {
+		req.Ratio = 1
+	}
+	if req.Ratio < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ratio must be positive",
+		})
+	}
+
+	if uint(ingredientID) == req.SubstituteID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "An ingredient cannot substitute itself",
+		})
+	}
+
+	var count int64
+	database.DB.Model(&models.Ingredient{}).Where("id IN ?", []uint{uint(ingredientID), req.SubstituteID}).Count(&count)
+	if count != 2 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found",
+		})
+	}
+
+	substitution := models.IngredientSubstitution{
+		IngredientID:  uint(ingredientID),
+		SubstituteID:  req.SubstituteID,
+		Ratio:         req.Ratio,
+		Notes:         req.Notes,
+		DietaryReason: normalizeDietaryReason(req.DietaryReason),
+	}
+
+	var existing models.IngredientSubstitution
+	result := database.DB.Where("ingredient_id = ? AND substitute_id = ? AND dietary_reason = ?",
+		substitution.IngredientID, substitution.SubstituteID, substitution.DietaryReason).First(&existing)
+	if result.RowsAffected > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Substitute already exists",
+		})
+	}
+
+	if result := database.DB.Create(&substitution); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add substitute",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(convertSubstitutionToResponse(substitution))
+}
+
+// @Summary Delete ingredient substitute
+// @Description Remove a substitution from an ingredient
+// @Tags substitutions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Ingredient ID"
+// @Param substitution_id path int true "Substitution ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /ingredients/{id}/substitutes/{substitution_id} [delete]
+func DeleteIngredientSubstitute(c *fiber.Ctx) error {
+	ingredientID := c.Params("id")
+	substitutionID := c.Params("substitution_id")
+
+	result := database.DB.Where("id = ? AND ingredient_id = ?", substitutionID, ingredientID).Delete(&models.IngredientSubstitution{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete substitute",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Substitute not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Substitute deleted successfully",
+	})
+}
+
+// @Summary Swap a cart line for a substitute
+// @Description Replace an ingredient in user's cart with one of its substitutes, converting the quantity by the substitution ratio. Every line of the ingredient is swapped and merged into a substitute line whose unit it converts into. When the pair is listed more than once with different ratios, substitution_id must say which one is meant
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CartSwapRequest true "Swap details"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cart/swap [post]
+func SwapCartIngredient(c *fiber.Ctx) error {
+	var req models.CartSwapRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+	req.UserID = userID
+
+	if req.SubstituteID == 0 && req.SubstitutionID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "substitute_id or substitution_id is required",
+		})
+	}
+
+	query := database.DB.Where("ingredient_id = ?", req.IngredientID)
+	if req.SubstitutionID != 0 {
+		query = query.Where("id = ?", req.SubstitutionID)
+	}
+	if req.SubstituteID != 0 {
+		query = query.Where("substitute_id = ?", req.SubstituteID)
+	}
+
+	var substitutions []models.IngredientSubstitution
+	if result := query.Order("id").Find(&substitutions); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get substitutes",
+		})
+	}
+
+	if len(substitutions) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Substitute not found",
+		})
+	}
+
+	// The same pair can be listed for several dietary reasons; which one is
+	// meant only matters when their ratios differ.
+	substitution := substitutions[0]
+	for _, other := range substitutions[1:] {
+		if other.Ratio != substitution.Ratio {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Several substitutions match, pass substitution_id",
+			})
+		}
+	}
+
+	owner := activeCart(c)
+
+	var lines []models.Cart
+	if result := owner.lines(database.DB).Where("ingredient_id = ?", req.IngredientID).Order("id").Find(&lines); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch cart",
+		})
+	}
+
+	if len(lines) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ingredient not found in cart",
+		})
+	}
+
+	var events []models.CartEvent
+	var cartIDs []uint
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, line := range lines {
+			swapped, moved, err := moveCartLine(tx, line, substitution.SubstituteID, substitution.Ratio)
+			if err != nil {
+				return err
+			}
+			events = append(events, moved...)
+			cartIDs = append(cartIDs, swapped.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to swap cart ingredient",
+		})
+	}
+
+	for _, event := range events {
+		publishCartEvent(owner, event)
+	}
+
+	cartIDs = uniqueIDs(cartIDs)
+	var swapped models.Cart
+	database.DB.First(&swapped, cartIDs[0])
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Cart ingredient swapped successfully",
+		"id":       swapped.ID,
+		"quantity": swapped.Quantity,
+		"cart_ids": cartIDs,
+	})
+}
+
+// moveCartLine moves a cart line onto another ingredient, converting its
+// quantity by ratio and carrying the per-dish contributions along. The line is
+// merged into a line of that ingredient whose unit it converts into, as
+// addQuantityToCart would; otherwise it stays a line of its own. The events
+// describe the lines changed, to be published once the transaction commits.
+func moveCartLine(tx *gorm.DB, line models.Cart, ingredientID uint, ratio float64) (models.Cart, []models.CartEvent, error) {
+	original := line
+
+	quantity, unit := toCartQuantity(float64(line.Quantity)*ratio, line.Unit)
+	// Contributions follow the line into a finer unit.
+	unitScale, _ := utils.ConvertQuantity(1, line.Unit, unit)
+	if err := scaleContributions(tx, line.ID, ratio*unitScale); err != nil {
+		return line, nil, err
+	}
+	line.IngredientID = ingredientID
+	line.Quantity = quantity
+	line.Unit = unit
+
+	var targets []models.Cart
+	if err := cartOwnerOf(line).lines(tx).Where("ingredient_id = ? AND id <> ?", ingredientID, line.ID).Order("id").Find(&targets).Error; err != nil {
+		return line, nil, err
+	}
+	for _, target := range targets {
+		if _, ok := utils.ConvertQuantity(1, line.Unit, target.Unit); !ok {
+			continue
+		}
+
+		target, err := mergeCartLine(tx, line, target)
+		if err != nil {
+			return target, nil, err
+		}
+		original.Quantity = 0
+		return target, []models.CartEvent{
+			cartLineEvent(models.CartEventLineRemoved, original),
+			cartLineEvent(models.CartEventLineUpdated, target),
+		}, nil
+	}
+
+	if err := tx.Save(&line).Error; err != nil {
+		return line, nil, err
+	}
+	return line, []models.CartEvent{cartLineEvent(models.CartEventLineUpdated, line)}, nil
+}
+
+// mergeCartLine adds line, with its contributions, to target and deletes it.
+func mergeCartLine(tx *gorm.DB, line, target models.Cart) (models.Cart, error) {
+	unit, have, more := mergeAmounts(float64(target.Quantity), target.Unit, float64(line.Quantity), line.Unit)
+	if err := rescaleCartLine(tx, &target, unit, have); err != nil {
+		return target, err
+	}
+
+	// Only an amount still to buy puts a checked target back on the list.
+	added := ceilQuantity(more)
+	if line.Checked {
+		target.Quantity += added
+	} else {
+		growCartLine(&target, added)
+	}
+	if err := tx.Save(&target).Error; err != nil {
+		return target, err
+	}
+
+	var contributions []models.CartDishContribution
+	if err := tx.Where("cart_id = ?", line.ID).Find(&contributions).Error; err != nil {
+		return target, err
+	}
+	for _, contribution := range contributions {
+		converted, _ := utils.ConvertQuantity(float64(contribution.Quantity), line.Unit, target.Unit)
+		moved := ceilQuantity(converted)
+
+		var existing models.CartDishContribution
+		found := tx.Where("cart_id = ? AND dish_id = ?", target.ID, contribution.DishID).Limit(1).Find(&existing)
+		if found.Error != nil {
+			return target, found.Error
+		}
+
+		if found.RowsAffected > 0 {
+			existing.Quantity += moved
+			if err := tx.Save(&existing).Error; err != nil {
+				return target, err
+			}
+			if err := tx.Delete(&contribution).Error; err != nil {
+				return target, err
+			}
+			continue
+		}
+
+		contribution.CartID = target.ID
+		contribution.Quantity = moved
+		if err := tx.Save(&contribution).Error; err != nil {
+			return target, err
+		}
+	}
+
+	return target, tx.Delete(&line).Error
+}
+
+func scaleContributions(tx *gorm.DB, cartID uint, ratio float64) error {
+	if ratio == 1 {
+		return nil
+	}
+
+	var contributions []models.CartDishContribution
+	if err := tx.Where("cart_id = ?", cartID).Find(&contributions).Error; err != nil {
+		return err
+	}
+	for _, contribution := range contributions {
+		contribution.Quantity = ceilQuantity(float64(contribution.Quantity) * ratio)
+		if err := tx.Save(&contribution).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// substitutesFor returns, per ingredient, the first substitution declared for
+// the dietary reason.
+func substitutesFor(ingredientIDs []uint, reason string) map[uint]models.IngredientSubstitution {
+	substitutes := make(map[uint]models.IngredientSubstitution)
+	if len(ingredientIDs) == 0 || reason == "" {
+		return substitutes
+	}
+
+	var substitutions []models.IngredientSubstitution
+	database.DB.Where("ingredient_id IN ? AND dietary_reason = ?", ingredientIDs, reason).Order("id").Find(&substitutions)
+	for _, substitution := range substitutions {
+		if _, ok := substitutes[substitution.IngredientID]; !ok {
+			substitutes[substitution.IngredientID] = substitution
+		}
+	}
+	return substitutes
+}
+
+func convertSubstitutionToResponse(substitution models.IngredientSubstitution) models.SubstitutionResponse {
+	var substitute models.Ingredient
+	database.DB.First(&substitute, substitution.SubstituteID)
+
+	return models.SubstitutionResponse{
+		ID:            substitution.ID,
+		IngredientID:  substitution.IngredientID,
+		Substitute:    convertIngredientToResponse(substitute),
+		Ratio:         substitution.Ratio,
+		Notes:         substitution.Notes,
+		DietaryReason: substitution.DietaryReason,
+	}
+}
+
+func convertIngredientToResponse(ingredient models.Ingredient) models.IngredientResponse {
+	response := models.IngredientResponse{
+		ID:      ingredient.ID,
+		Name:    ingredient.Name,
+		Section: ingredient.Section,
+	}
+
+	if len(ingredient.Image) > 0 {
+		response.Image = base64.StdEncoding.EncodeToString(ingredient.Image)
+	}
+
+	return response
+}
+
+func normalizeDietaryReason(reason string) string {
+	return strings.ToLower(strings.TrimSpace(reason))
+}
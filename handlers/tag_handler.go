@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"errors"
+	"foodapp/database"
+	"foodapp/models"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var tagTypes = map[string]bool{
+	models.TagTypeCuisine:  true,
+	models.TagTypeMealType: true,
+	models.TagTypeDiet:     true,
+	models.TagTypeOther:    true,
+}
+
+// @Summary Get tags
+// @Description Get all tags, optionally filtered by type (cuisine, meal_type, diet, other)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param type query string false "Tag type"
+// @Success 200 {array} models.TagResponse
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func GetTags(c *fiber.Ctx) error {
+	query := database.DB.Order("type, name")
+	if tagType := c.Query("type"); tagType != "" {
+		query = query.Where("type = ?", tagType)
+	}
+
+	var tags []models.Tag
+	if result := query.Find(&tags); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get tags",
+		})
+	}
+
+	response := []models.TagResponse{}
+	for _, tag := range tags {
+		response = append(response, convertTagToResponse(tag))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Add tag
+// @Description Add a new tag
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param tag body models.TagRequest true "Tag details"
+// @Success 201 {object} models.TagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/add [post]
+func AddTag(c *fiber.Ctx) error {
+	var req models.TagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	tag := models.Tag{
+		Name: normalizeTagName(req.Name),
+		Type: req.Type,
+	}
+	if msg := validateTag(tag); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	var existing models.Tag
+	if result := database.DB.Where("name = ?", tag.Name).First(&existing); result.RowsAffected > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Tag already exists",
+		})
+	}
+
+	if result := database.DB.Create(&tag); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add tag",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(convertTagToResponse(tag))
+}
+
+// @Summary Update tag
+// @Description Rename a tag or change its type
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Tag ID"
+// @Param tag body models.TagRequest true "Tag details"
+// @Success 200 {object} models.TagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id} [put]
+func UpdateTag(c *fiber.Ctx) error {
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tag ID",
+		})
+	}
+
+	var req models.TagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var tag models.Tag
+	if result := database.DB.First(&tag, tagID); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tag not found",
+		})
+	}
+
+	tag.Name = normalizeTagName(req.Name)
+	tag.Type = req.Type
+	if msg := validateTag(tag); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	var existing models.Tag
+	if result := database.DB.Where("name = ? AND id <> ?", tag.Name, tag.ID).First(&existing); result.RowsAffected > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Tag already exists",
+		})
+	}
+
+	if result := database.DB.Save(&tag); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update tag",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(convertTagToResponse(tag))
+}
+
+// @Summary Delete tag
+// @Description Delete a tag and remove it from all dishes
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Tag ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id} [delete]
+func DeleteTag(c *fiber.Ctx) error {
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tag ID",
+		})
+	}
+
+	var result *gorm.DB
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tagID).Delete(&models.DishTag{}).Error; err != nil {
+			return err
+		}
+		result = tx.Delete(&models.Tag{}, tagID)
+		return result.Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete tag",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tag not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Tag deleted successfully",
+	})
+}
+
+// @Summary Tag a dish
+// @Description Attach tags to a dish
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Param request body models.DishTagsRequest true "Tag IDs"
+// @Success 200 {array} models.TagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/tags [post]
+func AddDishTags(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	var req models.DishTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if len(req.TagIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Tag IDs are required",
+		})
+	}
+
+	if err := addTagsToDish(database.DB, dish.ID, req.TagIDs); err != nil {
+		if errors.Is(err, errUnknownTag) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Tag not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to tag dish",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(loadDishTags(dish.ID))
+}
+
+// @Summary Untag a dish
+// @Description Remove a tag from a dish
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Dish ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dishes/{id}/tags/{tag_id} [delete]
+func RemoveDishTag(c *fiber.Ctx) error {
+	dish, ok, err := findDishFromParam(c)
+	if !ok {
+		return err
+	}
+
+	tagID, err := strconv.Atoi(c.Params("tag_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tag ID",
+		})
+	}
+
+	result := database.DB.Where("dish_id = ? AND tag_id = ?", dish.ID, tagID).Delete(&models.DishTag{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove tag from dish",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Tag removed from dish successfully",
+	})
+}
+
+var errUnknownTag = errors.New("unknown tag")
+
+// addTagsToDish attaches tags to a dish, skipping ones it already has.
+func addTagsToDish(db *gorm.DB, dishID uint, tagIDs []uint) error {
+	tagIDs = uniqueIDs(tagIDs)
+
+	var count int64
+	if err := db.Model(&models.Tag{}).Where("id IN ?", tagIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(tagIDs) {
+		return errUnknownTag
+	}
+
+	for _, tagID := range tagIDs {
+		link := models.DishTag{DishID: dishID, TagID: tagID}
+		if err := db.Where(link).FirstOrCreate(&link).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadDishTags(dishID uint) []models.TagResponse {
+	var tags []models.Tag
+	database.DB.Joins("JOIN dish_tags ON dish_tags.tag_id = tags.id").
+		Where("dish_tags.dish_id = ?", dishID).
+		Order("tags.type, tags.name").
+		Find(&tags)
+
+	var response []models.TagResponse
+	for _, tag := range tags {
+		response = append(response, convertTagToResponse(tag))
+	}
+	return response
+}
+
+// parseTagFilter reads ?tags=a,b and ?match=any|all from the query.
+func parseTagFilter(c *fiber.Ctx) (tags []string, matchAll bool, err error) {
+	for _, name := range strings.Split(c.Query("tags"), ",") {
+		if name = normalizeTagName(name); name != "" {
+			tags = append(tags, name)
+		}
+	}
+
+	switch c.Query("match", "any") {
+	case "any":
+		return tags, false, nil
+	case "all":
+		return tags, true, nil
+	default:
+		return nil, false, errors.New("match must be any or all")
+	}
+}
+
+// applyTagFilter limits a dish query to dishes carrying any (or all) of the
+// given tag names.
+func applyTagFilter(query *gorm.DB, tags []string, matchAll bool) *gorm.DB {
+	if len(tags) == 0 {
+		return query
+	}
+
+	tagged := database.DB.Model(&models.DishTag{}).
+		Select("dish_tags.dish_id").
+		Joins("JOIN tags ON tags.id = dish_tags.tag_id").
+		Where("tags.name IN ?", tags)
+
+	if matchAll {
+		tagged = tagged.Group("dish_tags.dish_id").
+			Having("COUNT(DISTINCT tags.name) = ?", len(uniqueStrings(tags)))
+	}
+
+	return query.Where("dishes.id IN (?)", tagged)
+}
+
+// tagFacets counts how many of the given dishes carry each tag.
+func tagFacets(dishIDs []uint) []models.TagFacet {
+	facets := []models.TagFacet{}
+	if len(dishIDs) == 0 {
+		return facets
+	}
+
+	database.DB.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.type, COUNT(dish_tags.dish_id) AS count").
+		Joins("JOIN dish_tags ON dish_tags.tag_id = tags.id").
+		Where("dish_tags.dish_id IN ?", dishIDs).
+		Group("tags.id, tags.name, tags.type").
+		Order("count DESC, tags.name").
+		Scan(&facets)
+
+	return facets
+}
+
+func validateTag(tag models.Tag) string {
+	if tag.Name == "" {
+		return "Tag name is required"
+	}
+	if !tagTypes[tag.Type] {
+		return "Tag type must be one of cuisine, meal_type, diet, other"
+	}
+	return ""
+}
+
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func convertTagToResponse(tag models.Tag) models.TagResponse {
+	return models.TagResponse{
+		ID:   tag.ID,
+		Name: tag.Name,
+		Type: tag.Type,
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -6,6 +6,8 @@ import (
 	"foodapp/models"
 	"foodapp/utils"
 	"gorm.io/gorm"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
@@ -106,6 +108,7 @@ func LoginUser(c *fiber.Ctx) error {
 		ID:       user.ID,
 		UserName: user.UserName,
 		Email:    user.Email,
+		Timezone: userLocationName(user),
 	}
 
 	if len(user.ProfileImage) > 0 {
@@ -141,6 +144,7 @@ func GetUserProfile(c *fiber.Ctx) error {
 		ID:       user.ID,
 		UserName: user.UserName,
 		Email:    user.Email,
+		Timezone: userLocationName(user),
 	}
 
 	if len(user.ProfileImage) > 0 {
@@ -182,6 +186,130 @@ func UpdateProfileImage(c *fiber.Ctx) error {
 	})
 }
 
+// @Summary Update timezone
+// @Description Set the current user's time zone, an IANA name such as Europe/Berlin. It decides where the user's days begin in the nutrition diary
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.TimezoneRequest true "Time zone"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/profile/timezone [put]
+func UpdateUserTimezone(c *fiber.Ctx) error {
+	var req models.TimezoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	name := strings.TrimSpace(req.Timezone)
+	location, err := time.LoadLocation(name)
+	if name == "" || name == "Local" || err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Unknown timezone",
+		})
+	}
+
+	userID := c.Locals("userID").(uint)
+
+	if result := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("timezone", location.String()); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update timezone",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Timezone updated successfully",
+		"timezone": location.String(),
+	})
+}
+
+// @Summary Set user role
+// @Description Give a user the user, editor or admin role. Admins can't take the admin role away from themselves
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path int true "User ID"
+// @Param request body models.RoleRequest true "Role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{user_id}/role [put]
+func UpdateUserRole(c *fiber.Ctx) error {
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	known := false
+	for _, candidate := range models.Roles {
+		if role == candidate {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role must be one of " + strings.Join(models.Roles, ", "),
+		})
+	}
+
+	var user models.User
+	if result := database.DB.Select("id", "role").First(&user, c.Params("user_id")); result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	if user.ID == c.Locals("userID").(uint) && role != models.RoleAdmin {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Admins can't demote themselves",
+		})
+	}
+
+	if result := database.DB.Model(&user).Update("role", role); result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update role",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role updated successfully",
+		"user_id": user.ID,
+		"role":    role,
+	})
+}
+
+// userLocation is the time zone the user's days are counted in, UTC unless
+// they set one.
+func userLocation(userID uint) *time.Location {
+	var user models.User
+	if result := database.DB.Select("id", "timezone").First(&user, userID); result.Error != nil {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(userLocationName(user))
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func userLocationName(user models.User) string {
+	if user.Timezone == "" {
+		return time.UTC.String()
+	}
+	return user.Timezone
+}
+
 func DeleteUser(c *fiber.Ctx) error {
 	userID := c.Params("user_id")
 
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"foodapp/config"
 	"foodapp/database"
 	"foodapp/routes"
+	"foodapp/service"
 	"log"
+	// Users' time zones must load even where the host has no zoneinfo.
+	_ "time/tzdata"
 
 	_ "foodapp/docs"
 
@@ -33,6 +37,12 @@ func main() {
 
 	database.MigrateDB()
 
+	if err := database.PromoteAdmins(cf.AdminEmails); err != nil {
+		log.Fatalf("Failed to promote admins: %v", err)
+	}
+
+	go service.RunPantryExpiryAlerts(context.Background(), database.DB, cf.PantryAlertInterval, cf.PantryAlertDays)
+
 	app := fiber.New(fiber.Config{
 		BodyLimit: 10 * 2048 * 2048,
 	})
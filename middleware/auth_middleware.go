@@ -33,3 +33,26 @@ func AuthRequired() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// AuthOptional identifies the caller when a valid bearer token is sent but
+// lets every other request through anonymously, for public endpoints that
+// personalise results. A stale or broken token is ignored rather than
+// refused, so it never locks anyone out of a public page.
+func AuthOptional() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			return c.Next()
+		}
+
+		claims, err := utils.ValidateJWT(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			return c.Next()
+		}
+
+		c.Locals("userID", claims.UserID)
+		c.Locals("userEmail", claims.Email)
+
+		return c.Next()
+	}
+}
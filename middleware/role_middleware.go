@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"foodapp/database"
+	"foodapp/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoleRequired only lets through users holding one of the given roles. It must
+// run after AuthRequired.
+func RoleRequired(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized - Missing or invalid authorization token",
+			})
+		}
+
+		var user models.User
+		if result := database.DB.Select("id", "role").First(&user, userID); result.Error != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized - User not found",
+			})
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Locals("userRole", user.Role)
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Forbidden - Insufficient permissions",
+		})
+	}
+}
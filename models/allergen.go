@@ -0,0 +1,56 @@
+package models
+
+// Allergens are the 14 allergens that EU Regulation 1169/2011 requires to be
+// declared on food.
+var Allergens = []string{
+	"celery",
+	"gluten",
+	"crustaceans",
+	"eggs",
+	"fish",
+	"lupin",
+	"milk",
+	"molluscs",
+	"mustard",
+	"tree_nuts",
+	"peanuts",
+	"sesame",
+	"soya",
+	"sulphites",
+}
+
+type IngredientAllergen struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	IngredientID uint   `gorm:"uniqueIndex:idx_ingredient_allergen" json:"ingredient_id"`
+	Allergen     string `gorm:"uniqueIndex:idx_ingredient_allergen" json:"allergen"`
+}
+
+// UserAllergen is an allergen the user must avoid.
+type UserAllergen struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	UserID   uint   `gorm:"uniqueIndex:idx_user_allergen" json:"user_id"`
+	Allergen string `gorm:"uniqueIndex:idx_user_allergen" json:"allergen"`
+}
+
+// UserDiet is a diet tag (see TagTypeDiet) every dish shown to the user must
+// carry, e.g. "vegan".
+type UserDiet struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"uniqueIndex:idx_user_diet" json:"user_id"`
+	Diet   string `gorm:"uniqueIndex:idx_user_diet" json:"diet"`
+}
+
+type AllergensRequest struct {
+	Allergens []string `json:"allergens"`
+}
+
+type DietaryProfile struct {
+	Allergens []string `json:"allergens"`
+	Diets     []string `json:"diets"`
+}
+
+// DietaryConflicts lists why a dish does not fit the caller's profile.
+type DietaryConflicts struct {
+	Allergens []string `json:"allergens,omitempty"`
+	Diets     []string `json:"diets,omitempty"`
+}
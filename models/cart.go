@@ -1,10 +1,31 @@
 package models
 
+import "time"
+
+// Cart is one line of a shopping cart. Lines with a HouseholdID belong to that
+// household's shared cart; the others to UserID's own cart. AddedBy is the
+// user who put the line in the cart. Checked lines have been picked up on the
+// current shopping trip.
 type Cart struct {
-	ID           uint `gorm:"primaryKey" json:"id"`
-	UserID       uint `json:"user_id" gorm:"constraint:OnDelete:CASCADE;"`
-	IngredientID uint `json:"ingredient_id" gorm:"constraint:OnDelete:CASCADE;"`
-	Quantity     int  `json:"quantity"`
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `json:"user_id" gorm:"constraint:OnDelete:CASCADE;"`
+	HouseholdID  *uint      `json:"household_id,omitempty" gorm:"index"`
+	IngredientID uint       `json:"ingredient_id" gorm:"constraint:OnDelete:CASCADE;"`
+	Quantity     int        `json:"quantity"`
+	Unit         string     `json:"unit"`
+	AddedBy      uint       `json:"added_by"`
+	Checked      bool       `json:"checked"`
+	CheckedAt    *time.Time `json:"checked_at,omitempty"`
+}
+
+// CartDishContribution records how much of a cart line was added by a dish,
+// so removing that dish later only takes back its own share.
+type CartDishContribution struct {
+	ID       uint `gorm:"primaryKey" json:"id"`
+	CartID   uint `json:"cart_id" gorm:"index;constraint:OnDelete:CASCADE;"`
+	UserID   uint `json:"user_id" gorm:"index"`
+	DishID   uint `json:"dish_id" gorm:"index"`
+	Quantity int  `json:"quantity"`
 }
 
 type CartRequest struct {
@@ -17,11 +38,60 @@ type CartResponse struct {
 	ID         uint `json:"id"`
 	UserID     uint `json:"user_id"`
 	Ingredient struct {
-		ID    uint   `json:"id"`
-		Name  string `json:"name"`
-		Image string `json:"image,omitempty"`
+		ID      uint   `json:"id"`
+		Name    string `json:"name"`
+		Section string `json:"section,omitempty"`
+		Image   string `json:"image,omitempty"`
 	} `json:"ingredient"`
-	Quantity int `json:"quantity"`
+	Quantity      int              `json:"quantity"`
+	Unit          string           `json:"unit,omitempty"`
+	Sources       []CartLineSource `json:"sources,omitempty"`
+	HouseholdID   *uint            `json:"household_id,omitempty"`
+	AddedBy       uint             `json:"added_by"`
+	AddedByName   string           `json:"added_by_name,omitempty"`
+	Checked       bool             `json:"checked"`
+	CheckedAt     *time.Time       `json:"checked_at,omitempty"`
+	EstimatedCost *float64         `json:"estimated_cost,omitempty"`
+}
+
+// CartLineSource is the part of a cart line that came from a dish.
+type CartLineSource struct {
+	DishID   uint `json:"dish_id"`
+	Quantity int  `json:"quantity"`
+}
+
+// CartAddDishRequest adds a dish's ingredients to the cart. Amounts already in
+// the user's pantry are skipped unless IgnorePantry is set.
+type CartAddDishRequest struct {
+	UserID       uint `json:"user_id"`
+	DishID       uint `json:"dish_id" validate:"required"`
+	Servings     int  `json:"servings,omitempty"`
+	IgnorePantry bool `json:"ignore_pantry,omitempty"`
+}
+
+// CartPlanLine is one ingredient of the shopping list for a meal plan. All
+// amounts are in Unit; ToBuy is what goes into the cart after the pantry.
+type CartPlanLine struct {
+	IngredientID   uint    `json:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name"`
+	Needed         float64 `json:"needed"`
+	FromPantry     float64 `json:"from_pantry"`
+	ToBuy          int     `json:"to_buy"`
+	Unit           string  `json:"unit,omitempty"`
+	CartID         uint    `json:"cart_id,omitempty"`
+}
+
+type CartFromPlanResponse struct {
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	DryRun bool           `json:"dry_run"`
+	Meals  int            `json:"meals"`
+	Lines  []CartPlanLine `json:"lines"`
+}
+
+type CartRemoveDishRequest struct {
+	UserID uint `json:"user_id"`
+	DishID uint `json:"dish_id" validate:"required"`
 }
 
 type CartRemoveIngredientRequest struct {
@@ -34,3 +104,33 @@ type CartUpdateQuantityRequest struct {
 	IngredientID uint `json:"ingredient_id" validate:"required"`
 	Quantity     int  `json:"quantity" validate:"required"`
 }
+
+// CartCheckRequest checks or unchecks the given lines, or every line of the
+// cart when All is set.
+type CartCheckRequest struct {
+	LineIDs []uint `json:"line_ids"`
+	All     bool   `json:"all"`
+	Checked bool   `json:"checked"`
+}
+
+const (
+	CartEventLineAdded    = "line_added"
+	CartEventLineUpdated  = "line_updated"
+	CartEventLineRemoved  = "line_removed"
+	CartEventCleared      = "cart_cleared"
+	CartEventTripFinished = "trip_finished"
+)
+
+// CartEvent is pushed to clients watching a cart when one of its lines
+// changes. ActorID is the user who made the change.
+type CartEvent struct {
+	Type         string    `json:"type"`
+	LineID       uint      `json:"line_id,omitempty"`
+	IngredientID uint      `json:"ingredient_id,omitempty"`
+	Quantity     int       `json:"quantity"`
+	Unit         string    `json:"unit,omitempty"`
+	Checked      bool      `json:"checked"`
+	HouseholdID  *uint     `json:"household_id,omitempty"`
+	ActorID      uint      `json:"actor_id"`
+	At           time.Time `json:"at"`
+}
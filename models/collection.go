@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// DefaultCollectionName is the name given to the collection created for the
+// legacy flat favorites list.
+const DefaultCollectionName = "Favorites"
+
+// FavoriteCollection is a named group of favorite dishes (a cookbook). Every
+// user with favorites has exactly one default collection.
+type FavoriteCollection struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CollectionRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type CollectionDishRequest struct {
+	DishID uint   `json:"dish_id" validate:"required"`
+	Note   string `json:"note,omitempty"`
+}
+
+type CollectionNoteRequest struct {
+	Note string `json:"note"`
+}
+
+type CollectionOrderRequest struct {
+	DishIDs []uint `json:"dish_ids" validate:"required"`
+}
+
+type CollectionResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"is_default"`
+	DishCount int64     `json:"dish_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CollectionDishResponse struct {
+	DishWithIngredients
+	Note     string `json:"note,omitempty"`
+	Position int    `json:"position"`
+}
+
+type CollectionWithDishes struct {
+	CollectionResponse
+	Dishes []CollectionDishResponse `json:"dishes"`
+}
@@ -10,6 +10,7 @@ type Dish struct {
 	Fats              int       `json:"fats"`
 	Carbs             int       `json:"carbs"`
 	Proteins          int       `json:"proteins"`
+	Servings          int       `gorm:"default:1" json:"servings"`
 	Category          string    `json:"category"`
 	Image             []byte    `gorm:"type:longblob" json:"image,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
@@ -25,6 +26,7 @@ type DishResponse struct {
 	Fats              int       `json:"fats"`
 	Carbs             int       `json:"carbs"`
 	Proteins          int       `json:"proteins"`
+	Servings          int       `json:"servings"`
 	Category          string    `json:"category"`
 	Image             string    `json:"image,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
@@ -35,6 +37,23 @@ type DishResponse struct {
 type DishWithIngredients struct {
 	Dish        Dish                `json:"dish"`
 	Ingredients []IngredientDetails `json:"ingredients"`
+	Servings    int                 `json:"servings,omitempty"`
+	PerServing  *Nutrition          `json:"per_serving,omitempty"`
+	Total       *Nutrition          `json:"total,omitempty"`
+	Tags        []TagResponse       `json:"tags,omitempty"`
+	Allergens   []string            `json:"allergens,omitempty"`
+	Conflicts   *DietaryConflicts   `json:"conflicts,omitempty"`
+	Rating      *RatingSummary      `json:"rating,omitempty"`
+	Cost        *DishCost           `json:"cost,omitempty"`
+}
+
+// Nutrition values of a dish; Dish.Calories, Fats, Carbs and Proteins are
+// stored per serving.
+type Nutrition struct {
+	Calories float64 `json:"calories"`
+	Fats     float64 `json:"fats"`
+	Carbs    float64 `json:"carbs"`
+	Proteins float64 `json:"proteins"`
 }
 
 type IngredientDetails struct {
@@ -42,6 +61,7 @@ type IngredientDetails struct {
 	Name     string  `json:"name"`
 	Image    string  `json:"image,omitempty"`
 	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit,omitempty"`
 }
 
 type CreateDishRequest struct {
@@ -51,16 +71,19 @@ type CreateDishRequest struct {
 	Fats              int                     `json:"fats" validate:"required"`
 	Carbs             int                     `json:"carbs" validate:"required"`
 	Proteins          int                     `json:"proteins" validate:"required"`
+	Servings          int                     `json:"servings,omitempty"`
 	Category          string                  `json:"category" validate:"required"`
 	Image             []byte                  `json:"image,omitempty"`
 	Instruction       string                  `json:"instruction" validate:"required"`
 	VideoInstructions []byte                  `json:"video_instructions,omitempty"`
 	Ingredients       []DishIngredientRequest `json:"ingredients"`
+	TagIDs            []uint                  `json:"tag_ids,omitempty"`
 }
 
 type DishIngredientRequest struct {
 	IngredientID uint    `json:"ingredient_id" validate:"required"`
 	Quantity     float64 `json:"quantity" validate:"required"`
+	Unit         string  `json:"unit,omitempty"`
 }
 
 type UpdatePictureRequest struct {
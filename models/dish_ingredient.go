@@ -5,15 +5,20 @@ type DishIngredient struct {
 	DishID       uint    `json:"dish_id"`
 	IngredientID uint    `json:"ingredient_id"`
 	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
 }
 
 type DishIngredientResponse struct {
-	DishID     uint               `json:"dish_id"`
-	Ingredient IngredientResponse `json:"ingredient"`
-	Quantity   float64            `json:"quantity"`
+	DishID           uint                `json:"dish_id"`
+	Ingredient       IngredientResponse  `json:"ingredient"`
+	Quantity         float64             `json:"quantity"`
+	Unit             string              `json:"unit,omitempty"`
+	SubstitutedFor   *IngredientResponse `json:"substituted_for,omitempty"`
+	SubstitutionNote string              `json:"substitution_note,omitempty"`
 }
 type DishIngredientsRequest struct {
 	DishID       uint    `json:"dish_id"`
 	IngredientID uint    `json:"ingredient_id"`
 	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
 }
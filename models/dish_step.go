@@ -0,0 +1,36 @@
+package models
+
+// DishStep is one ordered step of a dish's instructions.
+type DishStep struct {
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	DishID          uint   `gorm:"index" json:"dish_id"`
+	Position        int    `json:"position"`
+	Text            string `json:"text"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	Image           []byte `gorm:"type:longblob" json:"image,omitempty"`
+}
+
+// DishStepIngredient links a step to the dish ingredients it uses.
+type DishStepIngredient struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	StepID       uint `gorm:"index" json:"step_id"`
+	IngredientID uint `json:"ingredient_id"`
+}
+
+type DishStepRequest struct {
+	Position        int    `json:"position,omitempty"`
+	Text            string `json:"text" validate:"required"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	Image           []byte `json:"image,omitempty"`
+	IngredientIDs   []uint `json:"ingredient_ids,omitempty"`
+}
+
+type DishStepResponse struct {
+	ID              uint   `json:"id"`
+	DishID          uint   `json:"dish_id"`
+	Position        int    `json:"position"`
+	Text            string `json:"text"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	Image           string `json:"image,omitempty"`
+	IngredientIDs   []uint `json:"ingredient_ids"`
+}
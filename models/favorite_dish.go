@@ -1,9 +1,14 @@
 package models
 
+// FavoriteDish is a dish saved in one of a user's collections. Dishes added
+// through /favorites-dishes land in the user's default collection.
 type FavoriteDish struct {
-	ID     uint `gorm:"primaryKey" json:"id"`
-	UserID uint `json:"user_id"`
-	DishID uint `json:"dish_id"`
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	UserID       uint   `json:"user_id"`
+	DishID       uint   `json:"dish_id"`
+	CollectionID uint   `gorm:"index" json:"collection_id"`
+	Note         string `json:"note,omitempty"`
+	Position     int    `json:"position"`
 }
 
 type FavoriteDishRequest struct {
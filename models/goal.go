@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// DefaultGoalTolerancePercent is how far, in percent, a day's intake may be
+// off each of its targets and still count as within the goal.
+const DefaultGoalTolerancePercent = 10
+
+// NutritionGoal holds a user's daily targets from EffectiveFrom until the
+// day before their next goal takes effect. EffectiveFrom is midnight UTC of
+// the day, which is a day in the user's time zone. Targets that are zero are
+// not tracked.
+type NutritionGoal struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"uniqueIndex:idx_goal_user_from" json:"user_id"`
+	EffectiveFrom time.Time `gorm:"uniqueIndex:idx_goal_user_from" json:"effective_from"`
+	Calories      float64   `json:"calories"`
+	Fats          float64   `json:"fats"`
+	Carbs         float64   `json:"carbs"`
+	Proteins      float64   `json:"proteins"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NutritionGoalRequest sets the goal taking effect on EffectiveFrom, today
+// when empty, replacing any goal set for that same day.
+type NutritionGoalRequest struct {
+	EffectiveFrom string    `json:"effective_from,omitempty"`
+	Targets       Nutrition `json:"targets" validate:"required"`
+}
+
+type NutritionGoalResponse struct {
+	ID            uint      `json:"id"`
+	EffectiveFrom string    `json:"effective_from"`
+	Targets       Nutrition `json:"targets"`
+}
+
+// NutritionGoalsResponse lists the user's goals by effective date. Current is
+// the goal in effect today, if any.
+type NutritionGoalsResponse struct {
+	Current *NutritionGoalResponse  `json:"current"`
+	Goals   []NutritionGoalResponse `json:"goals"`
+}
+
+// ProgressDay compares one day's intake with the goal in effect that day.
+// Delta is intake minus goal for the targets the goal tracks.
+type ProgressDay struct {
+	Date         string     `json:"date"`
+	Dishes       int        `json:"dishes"`
+	Nutrition    Nutrition  `json:"nutrition"`
+	Goal         *Nutrition `json:"goal,omitempty"`
+	Delta        *Nutrition `json:"delta,omitempty"`
+	WithinTarget bool       `json:"within_target"`
+}
+
+// ProgressResponse tracks intake against goals over a range of days. A
+// streak is a run of days within target; the current streak ends on the last
+// day of the range, or the day before when that is today and it isn't within
+// target yet. AverageDelta averages the deltas of the days with a goal.
+type ProgressResponse struct {
+	Timezone         string        `json:"timezone"`
+	From             string        `json:"from"`
+	To               string        `json:"to"`
+	TolerancePercent float64       `json:"tolerance_percent"`
+	Days             []ProgressDay `json:"days"`
+	DaysWithGoal     int           `json:"days_with_goal"`
+	DaysWithinTarget int           `json:"days_within_target"`
+	CurrentStreak    int           `json:"current_streak"`
+	LongestStreak    int           `json:"longest_streak"`
+	AverageDelta     *Nutrition    `json:"average_delta,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	HouseholdRoleOwner  = "owner"
+	HouseholdRoleMember = "member"
+
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusDeclined = "declined"
+)
+
+// Household is a group of users sharing one cart.
+type Household struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   uint      `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type HouseholdMember struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	HouseholdID uint      `gorm:"uniqueIndex:idx_household_member" json:"household_id"`
+	UserID      uint      `gorm:"uniqueIndex:idx_household_member" json:"user_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type HouseholdInvite struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	HouseholdID   uint      `gorm:"index" json:"household_id"`
+	InvitedUserID uint      `gorm:"index" json:"invited_user_id"`
+	InvitedBy     uint      `json:"invited_by"`
+	Status        string    `gorm:"default:pending" json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type HouseholdRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type HouseholdInviteRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ActiveHouseholdRequest selects which cart the cart endpoints work on; a
+// null household_id switches back to the personal cart.
+type ActiveHouseholdRequest struct {
+	HouseholdID *uint `json:"household_id"`
+}
+
+type HouseholdMemberResponse struct {
+	UserID   uint   `json:"user_id"`
+	UserName string `json:"user_name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+type HouseholdResponse struct {
+	ID        uint                      `json:"id"`
+	Name      string                    `json:"name"`
+	OwnerID   uint                      `json:"owner_id"`
+	Active    bool                      `json:"active"`
+	Members   []HouseholdMemberResponse `json:"members"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
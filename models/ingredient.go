@@ -1,18 +1,56 @@
 package models
 
 type Ingredient struct {
-	ID    uint   `gorm:"primaryKey" json:"id"`
-	Name  string `json:"name"`
-	Image []byte `gorm:"type:longblob" json:"image,omitempty"`
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	Name           string `json:"name"`
+	NormalizedName string `gorm:"index" json:"-"`
+	Section        string `json:"section,omitempty"`
+	Image          []byte `gorm:"type:longblob" json:"image,omitempty"`
+}
+
+// IngredientSynonym is another name (or a translation) for an ingredient.
+type IngredientSynonym struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	IngredientID   uint   `gorm:"index" json:"ingredient_id"`
+	Name           string `json:"name"`
+	NormalizedName string `gorm:"uniqueIndex" json:"-"`
+	Language       string `json:"language,omitempty"`
 }
 
 type IngredientResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Image string `json:"image,omitempty"`
+	ID        uint     `json:"id"`
+	Name      string   `json:"name"`
+	Section   string   `json:"section,omitempty"`
+	Image     string   `json:"image,omitempty"`
+	Allergens []string `json:"allergens,omitempty"`
 }
 
 type IngredientRequest struct {
-	Name  string `json:"name" validate:"required"`
-	Image []byte `json:"image,omitempty"`
+	Name      string   `json:"name" validate:"required"`
+	Section   string   `json:"section,omitempty"`
+	Image     []byte   `json:"image,omitempty"`
+	Allergens []string `json:"allergens,omitempty"`
+}
+
+type IngredientListResponse struct {
+	Items    []IngredientResponse `json:"items"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+	Total    int64                `json:"total"`
+}
+
+type IngredientSynonymRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Language string `json:"language,omitempty"`
+}
+
+type IngredientMergeRequest struct {
+	CanonicalID  uint   `json:"canonical_id" validate:"required"`
+	DuplicateIDs []uint `json:"duplicate_ids" validate:"required"`
+}
+
+// DuplicateIngredientGroup is a set of ingredients that normalize to the same name.
+type DuplicateIngredientGroup struct {
+	NormalizedName string               `json:"normalized_name"`
+	Ingredients    []IngredientResponse `json:"ingredients"`
 }
@@ -0,0 +1,117 @@
+package models
+
+import "time"
+
+// MealPlanDateLayout is how meal plan dates are written in requests and
+// responses.
+const MealPlanDateLayout = "2006-01-02"
+
+const (
+	MealSlotBreakfast = "breakfast"
+	MealSlotLunch     = "lunch"
+	MealSlotDinner    = "dinner"
+	MealSlotSnack     = "snack"
+)
+
+// MealSlots lists the meal slots in the order they come in a day.
+var MealSlots = []string{MealSlotBreakfast, MealSlotLunch, MealSlotDinner, MealSlotSnack}
+
+// MealPlanEntry is a dish the user plans to eat in a slot of a day. A slot can
+// hold several dishes. Date is midnight UTC of the planned day.
+type MealPlanEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index:idx_meal_plan_user_date" json:"user_id"`
+	Date      time.Time `gorm:"index:idx_meal_plan_user_date" json:"date"`
+	Slot      string    `json:"slot"`
+	DishID    uint      `gorm:"index" json:"dish_id"`
+	Servings  int       `json:"servings"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MealPlanRequest adds or updates a meal plan entry. Date is in
+// MealPlanDateLayout; Servings defaults to one.
+type MealPlanRequest struct {
+	Date     string `json:"date" validate:"required"`
+	Slot     string `json:"slot" validate:"required"`
+	DishID   uint   `json:"dish_id" validate:"required"`
+	Servings int    `json:"servings,omitempty"`
+}
+
+// MealPlanCopyRequest copies the week starting on or containing FromWeek to
+// the week of ToWeek, the following week when ToWeek is empty. Replace clears
+// the target week first.
+type MealPlanCopyRequest struct {
+	FromWeek string `json:"from_week" validate:"required"`
+	ToWeek   string `json:"to_week,omitempty"`
+	Replace  bool   `json:"replace"`
+}
+
+type MealPlanEntryResponse struct {
+	ID        uint      `json:"id"`
+	Date      string    `json:"date"`
+	Slot      string    `json:"slot"`
+	DishID    uint      `json:"dish_id"`
+	DishName  string    `json:"dish_name"`
+	Servings  int       `json:"servings"`
+	Nutrition Nutrition `json:"nutrition"`
+}
+
+// MealPlanDaySummary is the nutrition of the meals planned on one day.
+// Deviation, set for generated plans, is how far the day is off its targets.
+type MealPlanDaySummary struct {
+	Date      string     `json:"date"`
+	Meals     int        `json:"meals"`
+	Nutrition Nutrition  `json:"nutrition"`
+	Deviation *Nutrition `json:"deviation,omitempty"`
+}
+
+// MealPlanSummary totals the planned nutrition over a range of days. The
+// daily average counts every day of the range, planned or not.
+type MealPlanSummary struct {
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	Days         []MealPlanDaySummary `json:"days"`
+	Total        Nutrition            `json:"total"`
+	DailyAverage Nutrition            `json:"daily_average"`
+}
+
+// MealPlanGenerateRequest fills the given slots of the days From to To with
+// dishes that together come close to the daily Targets. Targets that are zero
+// are ignored, except Calories which is required. Slots already planned are
+// kept unless Replace is set. The same Seed always gives the same plan; without
+// one a seed is picked and returned.
+type MealPlanGenerateRequest struct {
+	From             string    `json:"from" validate:"required"`
+	To               string    `json:"to" validate:"required"`
+	Slots            []string  `json:"slots,omitempty"`
+	Targets          Nutrition `json:"targets" validate:"required"`
+	ExcludeTags      []string  `json:"exclude_tags,omitempty"`
+	ExcludeAllergens []string  `json:"exclude_allergens,omitempty"`
+	MaxPrepTime      int       `json:"max_prep_time,omitempty"`
+	Seed             *int64    `json:"seed,omitempty"`
+	Replace          bool      `json:"replace"`
+	DryRun           bool      `json:"dry_run"`
+}
+
+type MealPlanGenerateResponse struct {
+	Seed    int64                   `json:"seed"`
+	DryRun  bool                    `json:"dry_run"`
+	Targets Nutrition               `json:"targets"`
+	Entries []MealPlanEntryResponse `json:"entries"`
+	Days    []MealPlanDaySummary    `json:"days"`
+}
+
+// MealPlanFeed holds the secret token in a user's meal plan calendar URL.
+// Anyone with the URL can read the plan, so it can be revoked for a new one.
+type MealPlanFeed struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex" json:"user_id"`
+	Token     string    `gorm:"uniqueIndex" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MealPlanFeedResponse struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// DataMigration records that a one-off data migration has run, so it isn't
+// applied again on the next start.
+type DataMigration struct {
+	Name      string    `gorm:"primaryKey" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	NotificationKindPantryExpiry = "pantry_expiry"
+
+	// DefaultExpiryWindowDays is how many days ahead an item counts as
+	// expiring soon when no other window is given.
+	DefaultExpiryWindowDays = 3
+)
+
+// Notification is an in-app message for a user. Key identifies what the
+// notification is about, so a job that runs repeatedly notifies a user about
+// the same thing only once.
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"uniqueIndex:idx_notification_user_key" json:"user_id"`
+	Key       string     `gorm:"uniqueIndex:idx_notification_user_key" json:"-"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Items    []Notification `json:"items"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Total    int64          `json:"total"`
+	Unread   int64          `json:"unread"`
+}
+
+// UseUpSuggestion is a dish together with the soon-to-expire pantry items it
+// would use.
+type UseUpSuggestion struct {
+	DishWithIngredients
+	Expiring []PantryItemResponse `json:"expiring"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// PantryDateLayout is how pantry expiry dates are written in requests and
+// responses.
+const PantryDateLayout = "2006-01-02"
+
+// PantryItem is an amount of an ingredient the user has at home. A user can
+// hold several items of one ingredient, e.g. two packs with different expiry
+// dates.
+type PantryItem struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index" json:"user_id"`
+	IngredientID uint       `gorm:"index" json:"ingredient_id"`
+	Quantity     float64    `json:"quantity"`
+	Unit         string     `json:"unit"`
+	ExpiresOn    *time.Time `json:"expires_on,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PantryItemRequest adds or updates a pantry item. ExpiresOn is a date in
+// PantryDateLayout; empty means the item doesn't expire.
+type PantryItemRequest struct {
+	IngredientID uint    `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity" validate:"required,gt=0"`
+	Unit         string  `json:"unit"`
+	ExpiresOn    string  `json:"expires_on,omitempty"`
+}
+
+type PantryItemResponse struct {
+	ID             uint    `json:"id"`
+	IngredientID   uint    `json:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name"`
+	Quantity       float64 `json:"quantity"`
+	Unit           string  `json:"unit,omitempty"`
+	ExpiresOn      string  `json:"expires_on,omitempty"`
+	Expired        bool    `json:"expired"`
+}
+
+// PantryUsage is how much of an ingredient the pantry provided, in the unit
+// the recipe asked for.
+type PantryUsage struct {
+	IngredientID uint    `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// DefaultCurrency is used for cost estimates when the caller doesn't ask for
+// another currency.
+const DefaultCurrency = "EUR"
+
+// IngredientPrice is what one Unit of an ingredient costs, e.g. 2.40 EUR per
+// "kg". Prices with a Store only apply when shopping there; prices without
+// one apply anywhere.
+type IngredientPrice struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	IngredientID uint      `gorm:"index" json:"ingredient_id"`
+	Price        float64   `json:"price"`
+	Unit         string    `json:"unit"`
+	Currency     string    `json:"currency"`
+	Store        string    `json:"store,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type IngredientPriceRequest struct {
+	Price    float64 `json:"price" validate:"required,gt=0"`
+	Unit     string  `json:"unit"`
+	Currency string  `json:"currency"`
+	Store    string  `json:"store,omitempty"`
+}
+
+// CostEstimate is the estimated price of a set of ingredients. Unpriced lists
+// ingredients without a usable price, which the total leaves out.
+type CostEstimate struct {
+	Currency string   `json:"currency"`
+	Total    float64  `json:"total"`
+	Unpriced []string `json:"unpriced,omitempty"`
+}
+
+// DishCost is the estimated cost of a dish for the servings it is shown with.
+// Complete is false when some ingredients are unpriced, so the cost is only a
+// lower bound.
+type DishCost struct {
+	CostEstimate
+	PerServing float64 `json:"per_serving"`
+	Complete   bool    `json:"complete"`
+}
+
+// CartWithTotal is the cart together with its estimated cost. Lines or
+// Sections is set depending on how the cart was grouped.
+type CartWithTotal struct {
+	Lines    []CartResponse        `json:"lines,omitempty"`
+	Sections []CartSectionResponse `json:"sections,omitempty"`
+	Estimate CostEstimate          `json:"estimate"`
+}
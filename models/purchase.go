@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Purchase is a finished shopping trip: the cart lines that were checked off
+// when the user finished it. Trips on a household cart carry its HouseholdID.
+type Purchase struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UserID      uint           `gorm:"index" json:"user_id"`
+	HouseholdID *uint          `gorm:"index" json:"household_id,omitempty"`
+	FinishedAt  time.Time      `json:"finished_at"`
+	Items       []PurchaseItem `json:"items"`
+}
+
+// PurchaseItem is one bought line of a trip. The ingredient name is copied so
+// the history still reads after the ingredient is deleted.
+type PurchaseItem struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	PurchaseID     uint       `gorm:"index;constraint:OnDelete:CASCADE;" json:"purchase_id"`
+	IngredientID   uint       `gorm:"index" json:"ingredient_id"`
+	IngredientName string     `json:"ingredient_name"`
+	Quantity       int        `json:"quantity"`
+	Unit           string     `json:"unit,omitempty"`
+	AddedBy        uint       `json:"added_by"`
+	CheckedAt      *time.Time `json:"checked_at,omitempty"`
+}
+
+type PurchaseListResponse struct {
+	Items    []Purchase `json:"items"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	Total    int64      `json:"total"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// DishReview is a user's rating of a dish they have cooked. Hidden reviews
+// were taken down by an editor and are left out of listings and averages.
+type DishReview struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	DishID       uint      `gorm:"uniqueIndex:idx_dish_review_user" json:"dish_id"`
+	UserID       uint      `gorm:"uniqueIndex:idx_dish_review_user" json:"user_id"`
+	Rating       int       `json:"rating"`
+	Comment      string    `json:"comment,omitempty"`
+	Hidden       bool      `gorm:"index" json:"hidden"`
+	HiddenBy     uint      `json:"hidden_by,omitempty"`
+	HiddenReason string    `json:"hidden_reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type DishReviewRequest struct {
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type ReviewModerationRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type DishReviewResponse struct {
+	ID        uint      `json:"id"`
+	DishID    uint      `json:"dish_id"`
+	UserID    uint      `json:"user_id"`
+	UserName  string    `json:"user_name"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment,omitempty"`
+	Hidden    bool      `json:"hidden,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int64   `json:"count"`
+}
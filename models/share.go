@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	ShareResourceCollection = "collection"
+	ShareResourceCart       = "cart"
+
+	SharePermissionRead = "read"
+	SharePermissionEdit = "edit"
+)
+
+// ShareLink is a revocable token that gives anyone holding it access to a
+// collection or a cart without signing in.
+type ShareLink struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index" json:"user_id"`
+	ResourceType string     `json:"resource_type"`
+	ResourceID   uint       `json:"resource_id"`
+	Token        string     `gorm:"uniqueIndex" json:"token"`
+	Permission   string     `json:"permission"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ShareAccess is one use of a share link. UserID is set when the visitor was
+// signed in.
+type ShareAccess struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ShareID   uint      `gorm:"index" json:"share_id"`
+	UserID    *uint     `json:"user_id,omitempty"`
+	Action    string    `json:"action"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ShareRequest struct {
+	ResourceType string     `json:"resource_type" validate:"required"`
+	ResourceID   uint       `json:"resource_id"`
+	Permission   string     `json:"permission"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// SharedResource is what a share token resolves to; only the field matching
+// ResourceType is set.
+type SharedResource struct {
+	ResourceType string                `json:"resource_type"`
+	Permission   string                `json:"permission"`
+	ExpiresAt    *time.Time            `json:"expires_at,omitempty"`
+	Collection   *CollectionWithDishes `json:"collection,omitempty"`
+	Cart         []CartResponse        `json:"cart,omitempty"`
+}
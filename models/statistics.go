@@ -25,3 +25,29 @@ type StatisticsResponse struct {
 	DishWithIngredients DishWithIngredients `json:"dish_ingredient"`
 	CreatedAt           time.Time           `json:"created_at"`
 }
+
+const (
+	DiaryGroupDay   = "day"
+	DiaryGroupWeek  = "week"
+	DiaryGroupMonth = "month"
+)
+
+// NutritionDiaryPeriod totals what was eaten from From to To, both local
+// dates in MealPlanDateLayout. Dishes counts the dishes eaten.
+type NutritionDiaryPeriod struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Dishes    int       `json:"dishes"`
+	Nutrition Nutrition `json:"nutrition"`
+}
+
+// NutritionDiaryResponse is the nutrition diary of a range of days, one period
+// per day, week or month. Periods with nothing eaten are included with zeros.
+type NutritionDiaryResponse struct {
+	Timezone string                 `json:"timezone"`
+	Group    string                 `json:"group"`
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	Periods  []NutritionDiaryPeriod `json:"periods"`
+	Total    NutritionDiaryPeriod   `json:"total"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// GrocerySections are the store sections an ingredient can be filed under,
+// in the order a typical store is laid out.
+var GrocerySections = []string{
+	"produce",
+	"bakery",
+	"deli",
+	"meat",
+	"seafood",
+	"dairy",
+	"frozen",
+	"pantry",
+	"spices",
+	"snacks",
+	"beverages",
+	"household",
+	"other",
+}
+
+// GrocerySectionOther is where ingredients without a section are listed.
+const GrocerySectionOther = "other"
+
+// StoreProfile is a store the user shops at, with the order they walk its
+// sections in. The default profile sorts the grouped cart when no store is
+// given.
+type StoreProfile struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StoreSection struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	StoreProfileID uint   `gorm:"uniqueIndex:idx_store_section" json:"store_profile_id"`
+	Section        string `gorm:"uniqueIndex:idx_store_section" json:"section"`
+	Position       int    `json:"position"`
+}
+
+// StoreProfileRequest lists sections in walking order. Sections left out are
+// walked last, in the order of GrocerySections.
+type StoreProfileRequest struct {
+	Name      string   `json:"name" validate:"required"`
+	Sections  []string `json:"sections"`
+	IsDefault bool     `json:"is_default"`
+}
+
+type StoreProfileResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"is_default"`
+	Sections  []string  `json:"sections"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CartSectionResponse is one section of a cart grouped by grocery section.
+type CartSectionResponse struct {
+	Section string         `json:"section"`
+	Lines   []CartResponse `json:"lines"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// IngredientSubstitution says Substitute can replace Ingredient, using Ratio
+// units of the substitute per unit of the original.
+type IngredientSubstitution struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	IngredientID  uint    `gorm:"index" json:"ingredient_id"`
+	SubstituteID  uint    `gorm:"index" json:"substitute_id"`
+	Ratio         float64 `gorm:"default:1" json:"ratio"`
+	Notes         string  `json:"notes,omitempty"`
+	DietaryReason string  `gorm:"index" json:"dietary_reason,omitempty"`
+}
+
+type SubstitutionRequest struct {
+	SubstituteID  uint    `json:"substitute_id" validate:"required"`
+	Ratio         float64 `json:"ratio,omitempty"`
+	Notes         string  `json:"notes,omitempty"`
+	DietaryReason string  `json:"dietary_reason,omitempty"`
+}
+
+type SubstitutionResponse struct {
+	ID            uint               `json:"id"`
+	IngredientID  uint               `json:"ingredient_id"`
+	Substitute    IngredientResponse `json:"substitute"`
+	Ratio         float64            `json:"ratio"`
+	Notes         string             `json:"notes,omitempty"`
+	DietaryReason string             `json:"dietary_reason,omitempty"`
+}
+
+// CartSwapRequest swaps a cart ingredient for a substitute. SubstitutionID
+// picks the substitution when the pair is listed more than once, for
+// different dietary reasons with different ratios.
+type CartSwapRequest struct {
+	UserID         uint `json:"user_id"`
+	IngredientID   uint `json:"ingredient_id" validate:"required"`
+	SubstituteID   uint `json:"substitute_id,omitempty"`
+	SubstitutionID uint `json:"substitution_id,omitempty"`
+}
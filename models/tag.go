@@ -0,0 +1,48 @@
+package models
+
+const (
+	TagTypeCuisine  = "cuisine"
+	TagTypeMealType = "meal_type"
+	TagTypeDiet     = "diet"
+	TagTypeOther    = "other"
+)
+
+type Tag struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"uniqueIndex" json:"name"`
+	Type string `gorm:"index" json:"type"`
+}
+
+type DishTag struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	DishID uint `gorm:"uniqueIndex:idx_dish_tag" json:"dish_id"`
+	TagID  uint `gorm:"uniqueIndex:idx_dish_tag" json:"tag_id"`
+}
+
+type TagRequest struct {
+	Name string `json:"name" validate:"required"`
+	Type string `json:"type" validate:"required"`
+}
+
+type TagResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type DishTagsRequest struct {
+	TagIDs []uint `json:"tag_ids" validate:"required"`
+}
+
+// TagFacet is the number of dishes in a result set carrying a tag.
+type TagFacet struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+type DishSearchResponse struct {
+	Dishes []DishWithIngredients `json:"dishes"`
+	Facets []TagFacet            `json:"facets"`
+}
@@ -6,13 +6,30 @@ type User struct {
 	Email        string `gorm:"unique" json:"email"`
 	PasswordHash string `json:"-"` // პაროლის ჰეში არ შედის JSON პასუხებში
 	ProfileImage []byte `gorm:"type:longblob" json:"profile_image,omitempty"`
+	Role         string `gorm:"default:user" json:"role"`
+	// ActiveHouseholdID selects the household cart the cart endpoints work
+	// on; nil means the user's own cart.
+	ActiveHouseholdID *uint `json:"active_household_id,omitempty"`
+	// Timezone is the IANA name of the user's time zone, used to tell where
+	// their days begin. Empty means UTC.
+	Timezone string `json:"timezone"`
 }
 
+const (
+	RoleUser   = "user"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Roles lists the roles a user can hold.
+var Roles = []string{RoleUser, RoleEditor, RoleAdmin}
+
 type UserResponse struct {
 	ID           uint   `json:"id"`
 	UserName     string `json:"user_name"`
 	Email        string `json:"email"`
 	ProfileImage string `json:"profile_image,omitempty"` // Base64 დაშიფრულია
+	Timezone     string `json:"timezone"`
 }
 
 type RegisterRequest struct {
@@ -35,3 +52,11 @@ type LoginResponse struct {
 type ImageUpdateRequest struct {
 	Image []byte `json:"image,omitempty"`
 }
+
+type TimezoneRequest struct {
+	Timezone string `json:"timezone" validate:"required"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
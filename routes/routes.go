@@ -3,6 +3,7 @@ package routes
 import (
 	"foodapp/handlers"
 	"foodapp/middleware"
+	"foodapp/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -56,6 +57,14 @@ func SetupRoutes(app *fiber.App) {
 	// @Router /users/profile/image [put]
 	userRoutes.Put("/profile/image", middleware.AuthRequired(), handlers.UpdateProfileImage)
 
+	userRoutes.Get("/profile/dietary", middleware.AuthRequired(), handlers.GetDietaryProfile)
+	userRoutes.Put("/profile/dietary", middleware.AuthRequired(), handlers.UpdateDietaryProfile)
+	userRoutes.Put("/profile/timezone", middleware.AuthRequired(), handlers.UpdateUserTimezone)
+	userRoutes.Get("/profile/goals", middleware.AuthRequired(), handlers.GetNutritionGoals)
+	userRoutes.Put("/profile/goals", middleware.AuthRequired(), handlers.SetNutritionGoal)
+
+	userRoutes.Put("/:user_id/role", middleware.AuthRequired(), middleware.RoleRequired(models.RoleAdmin), handlers.UpdateUserRole)
+
 	userRoutes.Delete("/delete/:user_id", middleware.AuthRequired(), handlers.DeleteUser)
 
 	// @Summary Get all dishes
@@ -66,7 +75,7 @@ func SetupRoutes(app *fiber.App) {
 	// @Success 200 {array} models.Dish
 	// @Router /dishes [get]
 	dishRoutes := app.Group("/dishes")
-	dishRoutes.Get("/", handlers.GetAllDishes)
+	dishRoutes.Get("/", middleware.AuthOptional(), handlers.GetAllDishes)
 
 	// @Summary Create new dish
 	// @Description Create a new dish with ingredients
@@ -87,7 +96,7 @@ func SetupRoutes(app *fiber.App) {
 	// @Param q query string true "Category name"
 	// @Success 200 {array} models.Dish
 	// @Router /dishes/category [get]
-	dishRoutes.Get("/category", handlers.GetDishesByCategory)
+	dishRoutes.Get("/category", middleware.AuthOptional(), handlers.GetDishesByCategory)
 
 	// @Summary Search dishes by name
 	// @Description Search for dishes by name
@@ -97,10 +106,37 @@ func SetupRoutes(app *fiber.App) {
 	// @Param q query string true "Search query"
 	// @Success 200 {array} models.Dish
 	// @Router /dishes/search [get]
-	dishRoutes.Get("/search", handlers.SearchDishesByName)
+	dishRoutes.Get("/search", middleware.AuthOptional(), handlers.SearchDishesByName)
 
 	dishRoutes.Put("/update-picture", handlers.UpdatePictureDishes)
 
+	dishRoutes.Get("/use-up", middleware.AuthRequired(), handlers.GetUseUpDishes)
+
+	dishRoutes.Get("/:id/steps", handlers.GetDishSteps)
+	dishRoutes.Post("/:id/steps", middleware.AuthRequired(), handlers.AddDishStep)
+	dishRoutes.Put("/:id/steps/:step_id", middleware.AuthRequired(), handlers.UpdateDishStep)
+	dishRoutes.Delete("/:id/steps/:step_id", middleware.AuthRequired(), handlers.DeleteDishStep)
+
+	dishRoutes.Get("/:id/allergens", handlers.GetDishAllergens)
+
+	dishRoutes.Get("/:id/reviews", handlers.GetDishReviews)
+	dishRoutes.Post("/:id/reviews", middleware.AuthRequired(), handlers.AddDishReview)
+	dishRoutes.Delete("/:id/reviews", middleware.AuthRequired(), handlers.DeleteDishReview)
+
+	reviewRoutes := app.Group("/reviews", middleware.AuthRequired(), middleware.RoleRequired(models.RoleEditor, models.RoleAdmin))
+	reviewRoutes.Get("/hidden", handlers.GetHiddenReviews)
+	reviewRoutes.Put("/:review_id/hide", handlers.HideReview)
+	reviewRoutes.Put("/:review_id/unhide", handlers.UnhideReview)
+
+	dishRoutes.Post("/:id/tags", middleware.AuthRequired(), handlers.AddDishTags)
+	dishRoutes.Delete("/:id/tags/:tag_id", middleware.AuthRequired(), handlers.RemoveDishTag)
+
+	tagRoutes := app.Group("/tags")
+	tagRoutes.Get("/", handlers.GetTags)
+	tagRoutes.Post("/add", middleware.AuthRequired(), handlers.AddTag)
+	tagRoutes.Put("/:id", middleware.AuthRequired(), handlers.UpdateTag)
+	tagRoutes.Delete("/:id", middleware.AuthRequired(), handlers.DeleteTag)
+
 	ingredientRoutes := app.Group("/ingredients")
 
 	// @Summary Add new ingredient
@@ -113,6 +149,25 @@ func SetupRoutes(app *fiber.App) {
 	// @Success 200 {object} models.Ingredient
 	// @Router /ingredients/add [post]
 	ingredientRoutes.Post("/add", middleware.AuthRequired(), handlers.AddIngredient)
+	ingredientRoutes.Get("/", handlers.GetIngredients)
+	ingredientRoutes.Get("/search", handlers.SearchIngredients)
+	ingredientRoutes.Get("/duplicates", middleware.AuthRequired(), middleware.RoleRequired(models.RoleAdmin), handlers.GetDuplicateIngredients)
+	ingredientRoutes.Post("/merge", middleware.AuthRequired(), middleware.RoleRequired(models.RoleAdmin), handlers.MergeIngredients)
+	ingredientRoutes.Get("/:id", handlers.GetIngredient)
+	ingredientRoutes.Put("/:id", middleware.AuthRequired(), handlers.UpdateIngredient)
+	ingredientRoutes.Delete("/:id", middleware.AuthRequired(), handlers.DeleteIngredient)
+	ingredientRoutes.Put("/:id/allergens", middleware.AuthRequired(), handlers.SetIngredientAllergens)
+	ingredientRoutes.Get("/:id/substitutes", handlers.GetIngredientSubstitutes)
+	ingredientRoutes.Post("/:id/substitutes", middleware.AuthRequired(), handlers.AddIngredientSubstitute)
+	ingredientRoutes.Delete("/:id/substitutes/:substitution_id", middleware.AuthRequired(), handlers.DeleteIngredientSubstitute)
+	ingredientRoutes.Get("/:id/prices", handlers.GetIngredientPrices)
+	ingredientRoutes.Post("/:id/prices", middleware.AuthRequired(), handlers.SetIngredientPrice)
+	ingredientRoutes.Delete("/:id/prices/:price_id", middleware.AuthRequired(), handlers.DeleteIngredientPrice)
+	ingredientRoutes.Get("/:id/synonyms", handlers.GetIngredientSynonyms)
+	ingredientRoutes.Post("/:id/synonyms", middleware.AuthRequired(), handlers.AddIngredientSynonym)
+	ingredientRoutes.Delete("/:id/synonyms/:synonym_id", middleware.AuthRequired(), handlers.DeleteIngredientSynonym)
+
+	app.Get("/allergens", handlers.GetAllergens)
 
 	// @Summary Add favorite dish
 	// @Description Add a dish to user's favorites
@@ -145,7 +200,75 @@ func SetupRoutes(app *fiber.App) {
 	// @Security ApiKeyAuth
 	// @Success 200 {array} models.Dish
 	// @Router /favorites-dishes/get [get]
-	favoritesRoutes.Get("/get", handlers.GetUserFavoriteDishes)
+	favoritesRoutes.Get("/get", middleware.AuthOptional(), handlers.GetUserFavoriteDishes)
+
+	app.Get("/stores/sections", handlers.GetGrocerySections)
+	storeRoutes := app.Group("/stores", middleware.AuthRequired())
+	storeRoutes.Get("/", handlers.GetStoreProfiles)
+	storeRoutes.Post("/", handlers.CreateStoreProfile)
+	storeRoutes.Get("/:id", handlers.GetStoreProfile)
+	storeRoutes.Put("/:id", handlers.UpdateStoreProfile)
+	storeRoutes.Delete("/:id", handlers.DeleteStoreProfile)
+
+	pantryRoutes := app.Group("/pantry", middleware.AuthRequired())
+	pantryRoutes.Get("/", handlers.GetPantry)
+	pantryRoutes.Post("/", handlers.AddPantryItem)
+	pantryRoutes.Put("/:id", handlers.UpdatePantryItem)
+	pantryRoutes.Delete("/:id", handlers.DeletePantryItem)
+
+	app.Get("/plans/feed.ics", handlers.GetMealPlanFeed)
+	planRoutes := app.Group("/plans", middleware.AuthRequired())
+	planRoutes.Get("/", handlers.GetMealPlan)
+	planRoutes.Post("/", handlers.AddMealPlanEntry)
+	planRoutes.Delete("/", handlers.ClearMealPlan)
+	planRoutes.Get("/summary", handlers.GetMealPlanSummary)
+	planRoutes.Post("/copy-week", handlers.CopyMealPlanWeek)
+	planRoutes.Post("/generate", handlers.GenerateMealPlan)
+	planRoutes.Get("/feed", handlers.GetMealPlanFeedURL)
+	planRoutes.Delete("/feed", handlers.RevokeMealPlanFeed)
+	planRoutes.Put("/:id", handlers.UpdateMealPlanEntry)
+	planRoutes.Delete("/:id", handlers.DeleteMealPlanEntry)
+
+	notificationRoutes := app.Group("/notifications", middleware.AuthRequired())
+	notificationRoutes.Get("/", handlers.GetNotifications)
+	notificationRoutes.Put("/read-all", handlers.MarkAllNotificationsRead)
+	notificationRoutes.Put("/:id/read", handlers.MarkNotificationRead)
+
+	collectionRoutes := app.Group("/collections", middleware.AuthRequired())
+	collectionRoutes.Get("/", handlers.GetCollections)
+	collectionRoutes.Post("/", handlers.CreateCollection)
+	collectionRoutes.Get("/:id", handlers.GetCollection)
+	collectionRoutes.Put("/:id", handlers.RenameCollection)
+	collectionRoutes.Delete("/:id", handlers.DeleteCollection)
+	collectionRoutes.Put("/:id/order", handlers.ReorderCollection)
+	collectionRoutes.Post("/:id/dishes", handlers.AddCollectionDish)
+	collectionRoutes.Put("/:id/dishes/:dish_id", handlers.UpdateCollectionDish)
+	collectionRoutes.Delete("/:id/dishes/:dish_id", handlers.RemoveCollectionDish)
+
+	householdRoutes := app.Group("/households", middleware.AuthRequired())
+	householdRoutes.Get("/", handlers.GetHouseholds)
+	householdRoutes.Post("/", handlers.CreateHousehold)
+	householdRoutes.Put("/active", handlers.SetActiveHousehold)
+	householdRoutes.Get("/invites", handlers.GetHouseholdInvites)
+	householdRoutes.Post("/invites/:invite_id/accept", handlers.AcceptHouseholdInvite)
+	householdRoutes.Post("/invites/:invite_id/decline", handlers.DeclineHouseholdInvite)
+	householdRoutes.Get("/:id", handlers.GetHousehold)
+	householdRoutes.Delete("/:id", handlers.DeleteHousehold)
+	householdRoutes.Post("/:id/invites", handlers.InviteToHousehold)
+	householdRoutes.Delete("/:id/members/:member_id", handlers.RemoveHouseholdMember)
+
+	shareRoutes := app.Group("/shares", middleware.AuthRequired())
+	shareRoutes.Get("/", handlers.GetShares)
+	shareRoutes.Post("/", handlers.CreateShare)
+	shareRoutes.Delete("/:id", handlers.RevokeShare)
+	shareRoutes.Get("/:id/accesses", handlers.GetShareAccesses)
+
+	sharedRoutes := app.Group("/shared", middleware.AuthOptional())
+	sharedRoutes.Get("/:token", handlers.GetSharedResource)
+	sharedRoutes.Post("/:token/cart", handlers.AddSharedCartIngredient)
+	sharedRoutes.Delete("/:token/cart/:ingredient_id", handlers.RemoveSharedCartIngredient)
+	sharedRoutes.Post("/:token/dishes", handlers.AddSharedCollectionDish)
+	sharedRoutes.Delete("/:token/dishes/:dish_id", handlers.RemoveSharedCollectionDish)
 
 	dishIngredientsRoutes := app.Group("/dishes-ingredients")
 	// @Summary Get dish ingredients
@@ -182,15 +305,35 @@ func SetupRoutes(app *fiber.App) {
 	// @Router /cart/get [get]
 	cartRoutes.Get("/get", middleware.AuthRequired(), handlers.GetUserCart)
 
+	cartRoutes.Get("/events", middleware.AuthRequired(), handlers.StreamCartEvents)
+
+	cartRoutes.Get("/export", middleware.AuthRequired(), handlers.ExportCart)
+
 	cartRoutes.Post("/remove-ingredients", middleware.AuthRequired(), handlers.RemoveIngredientsCart)
 
+	cartRoutes.Post("/add-dish", middleware.AuthRequired(), handlers.AddDishToCart)
+
+	cartRoutes.Post("/from-plan", middleware.AuthRequired(), handlers.AddPlanToCart)
+
+	cartRoutes.Post("/remove-dish", middleware.AuthRequired(), handlers.RemoveDishFromCart)
+
+	cartRoutes.Post("/swap", middleware.AuthRequired(), handlers.SwapCartIngredient)
+
 	cartRoutes.Delete("/remove-all-ingredients", middleware.AuthRequired(), handlers.RemoveAllIngredientsCart)
 
 	cartRoutes.Put("update-quantity", middleware.AuthRequired(), handlers.UpdateQuantityCart)
 
+	cartRoutes.Put("/check", middleware.AuthRequired(), handlers.CheckCartLines)
+
+	cartRoutes.Post("/finish-trip", middleware.AuthRequired(), handlers.FinishTrip)
+
+	cartRoutes.Get("/history", middleware.AuthRequired(), handlers.GetPurchaseHistory)
+
 	statRoutes := app.Group("/statistics")
 
 	statRoutes.Get("/get/:user_id", middleware.AuthRequired(), handlers.GetStatistics)
+	statRoutes.Get("/diary", middleware.AuthRequired(), handlers.GetNutritionDiary)
+	statRoutes.Get("/progress", middleware.AuthRequired(), handlers.GetProgress)
 	statRoutes.Post("/add", middleware.AuthRequired(), handlers.AddStatistics)
 	statRoutes.Delete("/remove", middleware.AuthRequired(), handlers.RemoveStatistics)
 }
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"foodapp/models"
+	"sync"
+)
+
+// CartEventBroker fans cart events out to the clients subscribed to a cart.
+// Topics name one cart, e.g. "user:7" or "household:3". MemoryBroker serves a
+// single process; a broker backed by Redis or NATS can implement the same
+// interface once the API runs on several instances.
+type CartEventBroker interface {
+	Publish(topic string, event models.CartEvent)
+	// Subscribe returns the events published to topic from now on. Calling
+	// cancel stops delivery and closes the channel.
+	Subscribe(topic string) (events <-chan models.CartEvent, cancel func())
+}
+
+// CartEvents is the broker the cart handlers publish to.
+var CartEvents CartEventBroker = NewMemoryBroker()
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events to it are dropped.
+const subscriberBuffer = 32
+
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]map[chan models.CartEvent]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics: make(map[string]map[chan models.CartEvent]struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(topic string, event models.CartEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.topics[topic] {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe(topic string) (<-chan models.CartEvent, func()) {
+	subscriber := make(chan models.CartEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[chan models.CartEvent]struct{})
+	}
+	b.topics[topic][subscriber] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.topics[topic], subscriber)
+			if len(b.topics[topic]) == 0 {
+				delete(b.topics, topic)
+			}
+			close(subscriber)
+		})
+	}
+	return subscriber, cancel
+}
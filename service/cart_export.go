@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"foodapp/models"
+	"foodapp/utils"
+	"strconv"
+	"strings"
+)
+
+// CartExport is a rendered shopping list ready to download.
+type CartExport struct {
+	Body        []byte
+	ContentType string
+	Extension   string
+}
+
+// ErrUnknownExportFormat is returned by ExportCart for formats it can't render.
+var ErrUnknownExportFormat = errors.New("unknown export format")
+
+const shoppingListTitle = "Shopping list"
+
+// ExportCart renders a cart grouped by section as pdf, txt, md or csv.
+func ExportCart(sections []models.CartSectionResponse, format string) (CartExport, error) {
+	switch format {
+	case "txt":
+		return CartExport{Body: cartText(sections), ContentType: "text/plain; charset=utf-8", Extension: "txt"}, nil
+	case "md":
+		return CartExport{Body: cartMarkdown(sections), ContentType: "text/markdown; charset=utf-8", Extension: "md"}, nil
+	case "csv":
+		body, err := cartCSV(sections)
+		return CartExport{Body: body, ContentType: "text/csv; charset=utf-8", Extension: "csv"}, err
+	case "pdf":
+		return CartExport{Body: cartPDF(sections), ContentType: "application/pdf", Extension: "pdf"}, nil
+	}
+	return CartExport{}, ErrUnknownExportFormat
+}
+
+func cartText(sections []models.CartSectionResponse) []byte {
+	var b bytes.Buffer
+	b.WriteString(shoppingListTitle + "\n")
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n%s\n", strings.ToUpper(section.Section))
+		for _, line := range section.Lines {
+			fmt.Fprintf(&b, "%s %s\n", checkbox(line), cartLineText(line))
+		}
+	}
+	return b.Bytes()
+}
+
+func cartMarkdown(sections []models.CartSectionResponse) []byte {
+	var b bytes.Buffer
+	b.WriteString("# " + shoppingListTitle + "\n")
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", sectionTitle(section.Section))
+		for _, line := range section.Lines {
+			fmt.Fprintf(&b, "- %s %s\n", checkbox(line), cartLineText(line))
+		}
+	}
+	return b.Bytes()
+}
+
+func cartCSV(sections []models.CartSectionResponse) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"section", "ingredient", "quantity", "unit", "checked"})
+	for _, section := range sections {
+		for _, line := range section.Lines {
+			w.Write([]string{
+				section.Section,
+				line.Ingredient.Name,
+				strconv.Itoa(line.Quantity),
+				line.Unit,
+				strconv.FormatBool(line.Checked),
+			})
+		}
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+func cartPDF(sections []models.CartSectionResponse) []byte {
+	var lines []utils.PDFLine
+	for _, section := range sections {
+		lines = append(lines, utils.PDFLine{Text: sectionTitle(section.Section), Heading: true})
+		for _, line := range section.Lines {
+			lines = append(lines, utils.PDFLine{Text: checkbox(line) + " " + cartLineText(line)})
+		}
+	}
+	return utils.TextPDF(shoppingListTitle, lines)
+}
+
+// cartLineText reads "Flour (500 g)", or "Eggs (6)" for lines without a unit.
+func cartLineText(line models.CartResponse) string {
+	quantity := strconv.Itoa(line.Quantity)
+	if line.Unit != "" {
+		quantity += " " + line.Unit
+	}
+	return line.Ingredient.Name + " (" + quantity + ")"
+}
+
+func checkbox(line models.CartResponse) string {
+	if line.Checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func sectionTitle(section string) string {
+	if section == "" {
+		return section
+	}
+	return strings.ToUpper(section[:1]) + section[1:]
+}
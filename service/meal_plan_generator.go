@@ -0,0 +1,156 @@
+package service
+
+import (
+	"foodapp/models"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// mealSlotWeights is how large a share of the day's targets each meal slot
+// should cover, relative to the other slots being filled.
+var mealSlotWeights = map[string]float64{
+	models.MealSlotBreakfast: 3,
+	models.MealSlotLunch:     4,
+	models.MealSlotDinner:    4,
+	models.MealSlotSnack:     1,
+}
+
+// generateChoices is how many of the best fitting dishes a slot picks from at
+// random, so different seeds give different plans.
+const generateChoices = 3
+
+// MealPlanGeneration is the input of GenerateMealPlan.
+type MealPlanGeneration struct {
+	// Dishes are the candidates, one serving each.
+	Dishes []models.Dish
+	Days   []time.Time
+	// Slots are filled in the order given.
+	Slots []string
+	// Targets are per day; zero values are ignored.
+	Targets models.Nutrition
+	// Planned is what is already planned per day, keyed by the day in
+	// MealPlanDateLayout. Taken holds "day slot" keys that must be left alone.
+	Planned map[string]models.Nutrition
+	Taken   map[string]bool
+	Seed    int64
+}
+
+// GenerateMealPlan fills the free slots of every day with one serving of a
+// dish each. Slot by slot it takes the dishes whose nutrition comes closest to
+// the slot's share of what the day still needs, and picks one of the few best
+// at random. A dish is not repeated until every candidate has been used. The
+// same input always gives the same plan.
+func GenerateMealPlan(g MealPlanGeneration) []models.MealPlanEntry {
+	if len(g.Dishes) == 0 {
+		return nil
+	}
+
+	dishes := append([]models.Dish(nil), g.Dishes...)
+	sort.Slice(dishes, func(i, j int) bool { return dishes[i].ID < dishes[j].ID })
+
+	rng := rand.New(rand.NewSource(g.Seed))
+	used := make(map[uint]bool)
+	var entries []models.MealPlanEntry
+
+	for _, day := range g.Days {
+		date := day.Format(models.MealPlanDateLayout)
+		remaining := subtractNutrition(g.Targets, g.Planned[date])
+
+		var open []string
+		weightLeft := 0.0
+		for _, slot := range g.Slots {
+			if !g.Taken[date+" "+slot] {
+				open = append(open, slot)
+				weightLeft += mealSlotWeights[slot]
+			}
+		}
+
+		for _, slot := range open {
+			share := mealSlotWeights[slot] / weightLeft
+			want := scaleNutrition(remaining, share)
+
+			if len(used) == len(dishes) {
+				used = make(map[uint]bool)
+			}
+			dish := pickDish(dishes, used, want, g.Targets, rng)
+
+			used[dish.ID] = true
+			remaining = subtractNutrition(remaining, dishNutrition(dish))
+			weightLeft -= mealSlotWeights[slot]
+
+			entries = append(entries, models.MealPlanEntry{
+				Date:     day,
+				Slot:     slot,
+				DishID:   dish.ID,
+				Servings: 1,
+			})
+		}
+	}
+	return entries
+}
+
+// pickDish ranks the unused dishes by how far they are from want and picks
+// one of the best generateChoices.
+func pickDish(dishes []models.Dish, used map[uint]bool, want, targets models.Nutrition, rng *rand.Rand) models.Dish {
+	type scored struct {
+		dish  models.Dish
+		score float64
+	}
+
+	var ranked []scored
+	for _, dish := range dishes {
+		if !used[dish.ID] {
+			ranked = append(ranked, scored{dish, nutritionDistance(dishNutrition(dish), want, targets)})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score < ranked[j].score })
+
+	return ranked[rng.Intn(min(generateChoices, len(ranked)))].dish
+}
+
+// nutritionDistance is the squared distance between two nutrition values,
+// each value taken relative to its daily target. Values without a target
+// don't count.
+func nutritionDistance(a, b, targets models.Nutrition) float64 {
+	distance := 0.0
+	for _, pair := range [][3]float64{
+		{a.Calories, b.Calories, targets.Calories},
+		{a.Proteins, b.Proteins, targets.Proteins},
+		{a.Fats, b.Fats, targets.Fats},
+		{a.Carbs, b.Carbs, targets.Carbs},
+	} {
+		if pair[2] > 0 {
+			distance += math.Pow((pair[0]-pair[1])/pair[2], 2)
+		}
+	}
+	return distance
+}
+
+func dishNutrition(dish models.Dish) models.Nutrition {
+	return models.Nutrition{
+		Calories: float64(dish.Calories),
+		Fats:     float64(dish.Fats),
+		Carbs:    float64(dish.Carbs),
+		Proteins: float64(dish.Proteins),
+	}
+}
+
+func subtractNutrition(a, b models.Nutrition) models.Nutrition {
+	return models.Nutrition{
+		Calories: a.Calories - b.Calories,
+		Fats:     a.Fats - b.Fats,
+		Carbs:    a.Carbs - b.Carbs,
+		Proteins: a.Proteins - b.Proteins,
+	}
+}
+
+func scaleNutrition(n models.Nutrition, factor float64) models.Nutrition {
+	return models.Nutrition{
+		Calories: n.Calories * factor,
+		Fats:     n.Fats * factor,
+		Carbs:    n.Carbs * factor,
+		Proteins: n.Proteins * factor,
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"foodapp/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type expiringItem struct {
+	ID             uint
+	UserID         uint
+	ExpiresOn      time.Time
+	IngredientName string
+}
+
+// NotifyExpiringPantryItems creates a notification for every pantry item that
+// expires between the day of now and days later. Items already notified about
+// for their current expiry date are skipped. It returns how many
+// notifications were created.
+func NotifyExpiringPantryItems(db *gorm.DB, now time.Time, days int) (int, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	until := today.AddDate(0, 0, days)
+
+	var items []expiringItem
+	err := db.Model(&models.PantryItem{}).
+		Select("pantry_items.id, pantry_items.user_id, pantry_items.expires_on, ingredients.name AS ingredient_name").
+		Joins("JOIN ingredients ON ingredients.id = pantry_items.ingredient_id").
+		Where("pantry_items.expires_on >= ? AND pantry_items.expires_on <= ?", today, until).
+		Order("pantry_items.expires_on, pantry_items.id").
+		Scan(&items).Error
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, item := range items {
+		date := item.ExpiresOn.Format(models.PantryDateLayout)
+		notification := models.Notification{
+			UserID: item.UserID,
+			Key:    fmt.Sprintf("%s:%d:%s", models.NotificationKindPantryExpiry, item.ID, date),
+			Kind:   models.NotificationKindPantryExpiry,
+			Title:  item.IngredientName + " expires soon",
+			Body:   fmt.Sprintf("%s in your pantry expires %s.", item.IngredientName, expiryPhrase(item.ExpiresOn, today)),
+		}
+
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification)
+		if result.Error != nil {
+			return created, result.Error
+		}
+		created += int(result.RowsAffected)
+	}
+	return created, nil
+}
+
+func expiryPhrase(expiresOn, today time.Time) string {
+	switch int(expiresOn.Sub(today).Hours() / 24) {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	default:
+		return "on " + expiresOn.Format(models.PantryDateLayout)
+	}
+}
+
+// RunPantryExpiryAlerts runs NotifyExpiringPantryItems right away and then
+// every interval until ctx is done.
+func RunPantryExpiryAlerts(ctx context.Context, db *gorm.DB, interval time.Duration, days int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if created, err := NotifyExpiringPantryItems(db, time.Now(), days); err != nil {
+			log.Printf("Pantry expiry alerts failed: %v", err)
+		} else if created > 0 {
+			log.Printf("Created %d pantry expiry notifications", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
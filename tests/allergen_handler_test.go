@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAllergenApp() *fiber.App {
+	app := fiber.New()
+	withUser := func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	}
+	app.Get("/allergens", handlers.GetAllergens)
+	app.Put("/ingredients/:id/allergens", handlers.SetIngredientAllergens)
+	app.Get("/dishes/:id/allergens", handlers.GetDishAllergens)
+	app.Get("/users/profile/dietary", withUser, handlers.GetDietaryProfile)
+	app.Put("/users/profile/dietary", withUser, handlers.UpdateDietaryProfile)
+	app.Get("/dishes", withUser, handlers.GetAllDishes)
+	app.Get("/anonymous/dishes", handlers.GetAllDishes)
+	return app
+}
+
+func seedAllergenDishes() {
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Flour"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Tomato"})
+	database.DB.Create(&models.IngredientAllergen{IngredientID: 1, Allergen: "milk"})
+	database.DB.Create(&models.IngredientAllergen{IngredientID: 2, Allergen: "gluten"})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Pancakes"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 200})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 150})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Tomato Soup"})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 3, Quantity: 500})
+}
+
+func TestSetIngredientAllergens_UnknownAllergen(t *testing.T) {
+	setupTestDB()
+	app := setupAllergenApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+
+	requestBody, _ := json.Marshal(models.AllergensRequest{Allergens: []string{"milk", "gravel"}})
+	request := httptest.NewRequest(http.MethodPut, "/ingredients/1/allergens", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetDishAllergens_DerivedFromIngredients(t *testing.T) {
+	setupTestDB()
+	app := setupAllergenApp()
+	seedAllergenDishes()
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes/1/allergens", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var allergens []string
+	json.NewDecoder(resp.Body).Decode(&allergens)
+	assert.Equal(t, []string{"gluten", "milk"}, allergens)
+}
+
+func TestUpdateDietaryProfile_UnknownDiet(t *testing.T) {
+	setupTestDB()
+	app := setupAllergenApp()
+
+	requestBody, _ := json.Marshal(models.DietaryProfile{Diets: []string{"vegan"}})
+	request := httptest.NewRequest(http.MethodPut, "/users/profile/dietary", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetAllDishes_FlagsAndHidesProfileConflicts(t *testing.T) {
+	setupTestDB()
+	app := setupAllergenApp()
+	seedAllergenDishes()
+
+	requestBody, _ := json.Marshal(models.DietaryProfile{Allergens: []string{"Milk"}})
+	request := httptest.NewRequest(http.MethodPut, "/users/profile/dietary", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "/dishes", nil)
+	resp, _ = app.Test(request)
+	var flagged []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&flagged)
+	assert.Len(t, flagged, 2)
+	assert.Equal(t, []string{"milk"}, flagged[0].Conflicts.Allergens)
+	assert.Nil(t, flagged[1].Conflicts)
+
+	request = httptest.NewRequest(http.MethodGet, "/dishes?profile=hide", nil)
+	resp, _ = app.Test(request)
+	var hidden []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&hidden)
+	assert.Len(t, hidden, 1)
+	assert.Equal(t, "Tomato Soup", hidden[0].Dish.Name)
+
+	request = httptest.NewRequest(http.MethodGet, "/anonymous/dishes?profile=hide", nil)
+	resp, _ = app.Test(request)
+	var anonymous []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&anonymous)
+	assert.Len(t, anonymous, 2)
+	assert.Equal(t, []string{"gluten", "milk"}, anonymous[0].Allergens)
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"foodapp/config"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/middleware"
+	"foodapp/models"
+	"foodapp/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthOptional_IgnoresBadToken(t *testing.T) {
+	setupTestDB()
+	database.DB.Create(&models.Dish{ID: 1, Name: "Soup"})
+
+	app := fiber.New()
+	app.Get("/dishes", middleware.AuthOptional(), handlers.GetAllDishes)
+	app.Get("/whoami", middleware.AuthOptional(), func(c *fiber.Ctx) error {
+		if userID, ok := c.Locals("userID").(uint); ok {
+			return c.SendString(strconv.FormatUint(uint64(userID), 10))
+		}
+		return c.SendString("anonymous")
+	})
+
+	for _, header := range []string{"", "Bearer bad", "Basic dXNlcjpwYXNz"} {
+		request := httptest.NewRequest(http.MethodGet, "/dishes", nil)
+		request.Header.Set("Authorization", header)
+		resp, _ := app.Test(request)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode, header)
+
+		request = httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		request.Header.Set("Authorization", header)
+		resp, _ = app.Test(request)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "anonymous", string(body), header)
+	}
+
+	cf, err := config.LoadConfig()
+	assert.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.JWTClaims{UserID: 7, Email: "cook@example.com"}).SignedString([]byte(cf.JWTSecret))
+	if assert.NoError(t, err) {
+		request := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(request)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "7", string(body))
+	}
+}
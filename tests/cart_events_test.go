@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"foodapp/service"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCartEventsApp() *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	as := app.Group("/as/:user_id", func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return c.Next()
+	})
+	as.Get("/cart/events", handlers.StreamCartEvents)
+	as.Post("/cart/add-ingredients", handlers.AddIngredientsToCart)
+	as.Post("/cart/remove-ingredients", handlers.RemoveIngredientsCart)
+	as.Put("/cart/update-quantity", handlers.UpdateQuantityCart)
+	return app
+}
+
+// subscribeCartEvents opens the event stream over a real connection and
+// returns the decoded events once the server has subscribed.
+func subscribeCartEvents(t *testing.T, baseURL, userID string) (<-chan models.CartEvent, func()) {
+	resp, err := http.Get(baseURL + "/as/" + userID + "/cart/events")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	connected := make(chan struct{})
+	events := make(chan models.CartEvent, 8)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == ": connected" {
+				close(connected)
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var event models.CartEvent
+				json.Unmarshal([]byte(data), &event)
+				events <- event
+			}
+		}
+	}()
+
+	select {
+	case <-connected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("event stream did not connect")
+	}
+	return events, func() { resp.Body.Close() }
+}
+
+func nextCartEvent(t *testing.T, events <-chan models.CartEvent) models.CartEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no cart event received")
+		return models.CartEvent{}
+	}
+}
+
+func TestStreamCartEvents_PushesLineChanges(t *testing.T) {
+	setupTestDB()
+	database.DB.Create(&models.User{ID: 1, Email: "one@example.com"})
+	database.DB.Create(&models.User{ID: 2, Email: "two@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+
+	app := setupCartEventsApp()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go app.Listener(listener)
+	defer app.ShutdownWithTimeout(time.Second)
+
+	events, closeStream := subscribeCartEvents(t, "http://"+listener.Addr().String(), "1")
+	defer closeStream()
+
+	sendJSON(app, http.MethodPost, "/as/2/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 5})
+	sendJSON(app, http.MethodPost, "/as/1/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 2})
+
+	event := nextCartEvent(t, events)
+	assert.Equal(t, models.CartEventLineAdded, event.Type)
+	assert.Equal(t, uint(1), event.IngredientID)
+	assert.Equal(t, 2, event.Quantity)
+	assert.Equal(t, uint(1), event.ActorID)
+
+	sendJSON(app, http.MethodPut, "/as/1/cart/update-quantity", models.CartUpdateQuantityRequest{IngredientID: 1, Quantity: 3})
+	event = nextCartEvent(t, events)
+	assert.Equal(t, models.CartEventLineUpdated, event.Type)
+	assert.Equal(t, 5, event.Quantity)
+
+	sendJSON(app, http.MethodPost, "/as/1/cart/remove-ingredients", models.CartRemoveIngredientRequest{IngredientID: 1})
+	event = nextCartEvent(t, events)
+	assert.Equal(t, models.CartEventLineRemoved, event.Type)
+	assert.Equal(t, uint(1), event.IngredientID)
+}
+
+func TestMemoryBroker_TopicsAndCancel(t *testing.T) {
+	broker := service.NewMemoryBroker()
+
+	events, cancel := broker.Subscribe("household:1")
+	broker.Publish("household:2", models.CartEvent{Type: models.CartEventCleared})
+	broker.Publish("household:1", models.CartEvent{Type: models.CartEventLineAdded})
+
+	assert.Equal(t, models.CartEventLineAdded, (<-events).Type)
+
+	cancel()
+	cancel()
+	broker.Publish("household:1", models.CartEvent{Type: models.CartEventLineAdded})
+
+	_, open := <-events
+	assert.False(t, open)
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"flag"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"foodapp/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func setupCartExportApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/cart/export", handlers.ExportCart)
+	return app
+}
+
+func seedCartExport() {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour", Section: "pantry"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Crème fraîche", Section: "dairy"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Apples", Section: "produce"})
+	database.DB.Create(&models.Ingredient{ID: 4, Name: "Milk (whole), 1L", Section: "dairy"})
+	database.DB.Create(&models.Ingredient{ID: 5, Name: "Bin bags"})
+
+	checkedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, line := range []models.Cart{
+		{IngredientID: 1, Quantity: 500, Unit: "g"},
+		{IngredientID: 2, Quantity: 200, Unit: "ml"},
+		{IngredientID: 3, Quantity: 6, Checked: true, CheckedAt: &checkedAt},
+		{IngredientID: 4, Quantity: 2},
+		{IngredientID: 5, Quantity: 1},
+	} {
+		line.UserID = 1
+		line.AddedBy = 1
+		database.DB.Create(&line)
+	}
+}
+
+func TestExportCart_Golden(t *testing.T) {
+	setupTestDB()
+	app := setupCartExportApp()
+	seedCartExport()
+
+	for format, contentType := range map[string]string{
+		"txt": "text/plain; charset=utf-8",
+		"md":  "text/markdown; charset=utf-8",
+		"csv": "text/csv; charset=utf-8",
+		"pdf": "application/pdf",
+	} {
+		t.Run(format, func(t *testing.T) {
+			resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/cart/export?format="+format, nil))
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, contentType, resp.Header.Get(fiber.HeaderContentType))
+			assert.Equal(t, `attachment; filename="shopping-list.`+format+`"`, resp.Header.Get(fiber.HeaderContentDisposition))
+
+			body, _ := io.ReadAll(resp.Body)
+			golden := filepath.Join("testdata", "cart_export."+format)
+			if *updateGolden {
+				os.MkdirAll("testdata", 0o755)
+				os.WriteFile(golden, body, 0o644)
+			}
+
+			want, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(body))
+		})
+	}
+}
+
+func TestExportCart_InvalidFormat(t *testing.T) {
+	setupTestDB()
+	app := setupCartExportApp()
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/cart/export?format=docx", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTextPDF_Paginates(t *testing.T) {
+	var lines []utils.PDFLine
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, utils.PDFLine{Text: "Line " + strconv.Itoa(i)})
+	}
+
+	pdf := string(utils.TextPDF("Long list", lines))
+	assert.Contains(t, pdf, "/Count 3")
+	assert.Contains(t, pdf, "(Line 100)")
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCartFromPlanApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Post("/cart/from-plan", handlers.AddPlanToCart)
+	app.Get("/cart/get", handlers.GetUserCart)
+	return app
+}
+
+func seedPlannedWeek() {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Eggs"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Milk"})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Pancakes", Servings: 2})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 250, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 2, Unit: "pcs"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 3, Quantity: 500, Unit: "ml"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Omelette", Servings: 1})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 2, Quantity: 3, Unit: "pcs"})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 3, Quantity: 0.1, Unit: "l"})
+
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "breakfast", DishID: 1, Servings: 4})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-03"), Slot: "dinner", DishID: 2, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-04"), Slot: "lunch", DishID: 2, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 2, Date: mustPlanDate("2026-03-02"), Slot: "lunch", DishID: 1, Servings: 2})
+
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 1, Quantity: 0.2, Unit: "kg"})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 2, Quantity: 6, Unit: "pcs"})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 3, Quantity: 1, Unit: "l", AddedBy: 1})
+}
+
+func TestAddPlanToCart_DryRunThenMerge(t *testing.T) {
+	setupTestDB()
+	app := setupCartFromPlanApp()
+	seedPlannedWeek()
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-03-02&to=2026-03-03&dry_run=true", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var preview models.CartFromPlanResponse
+	json.NewDecoder(resp.Body).Decode(&preview)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, 2, preview.Meals)
+	assert.Equal(t, []models.CartPlanLine{
+		{IngredientID: 2, IngredientName: "Eggs", Needed: 7, FromPantry: 6, ToBuy: 1, Unit: "pcs"},
+		{IngredientID: 1, IngredientName: "Flour", Needed: 500, FromPantry: 200, ToBuy: 300, Unit: "g"},
+		{IngredientID: 3, IngredientName: "Milk", Needed: 1100, FromPantry: 0, ToBuy: 1100, Unit: "ml"},
+	}, preview.Lines)
+	assert.Len(t, getCartLines(app), 1)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-03-02&to=2026-03-03", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var added models.CartFromPlanResponse
+	json.NewDecoder(resp.Body).Decode(&added)
+	assert.False(t, added.DryRun)
+	assert.NotZero(t, added.Lines[0].CartID)
+
+	cart := getCartLines(app)
+	assert.Len(t, cart, 3)
+	quantities := make(map[string]int)
+	for _, line := range cart {
+		quantities[line.Ingredient.Name+" "+line.Unit] = line.Quantity
+	}
+	assert.Equal(t, map[string]int{"Flour g": 300, "Eggs pcs": 1, "Milk ml": 2100}, quantities)
+
+	var pantryCount int64
+	database.DB.Model(&models.PantryItem{}).Count(&pantryCount)
+	assert.Equal(t, int64(2), pantryCount)
+}
+
+func TestAddPlanToCart_IgnorePantryAndEmptyRange(t *testing.T) {
+	setupTestDB()
+	app := setupCartFromPlanApp()
+	seedPlannedWeek()
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?week=2026-03-02&dry_run=true&ignore_pantry=true", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var preview models.CartFromPlanResponse
+	json.NewDecoder(resp.Body).Decode(&preview)
+	assert.Equal(t, 3, preview.Meals)
+	assert.Equal(t, 10, preview.Lines[0].ToBuy)
+	assert.Equal(t, 500, preview.Lines[1].ToBuy)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-04-01&to=2026-04-07", nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-04-07&to=2026-04-01", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAddPlanToCart_KeepsUnconvertibleUnitsApart(t *testing.T) {
+	setupTestDB()
+	app := setupCartFromPlanApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Salt"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Brine", Servings: 1})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 200, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Fries", Servings: 1})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 1, Quantity: 2, Unit: "pinch"})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "lunch", DishID: 1, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "dinner", DishID: 2, Servings: 1})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 1, Unit: "tsp", AddedBy: 1})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-03-02&to=2026-03-02", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var added models.CartFromPlanResponse
+	json.NewDecoder(resp.Body).Decode(&added)
+	if assert.Len(t, added.Lines, 2) {
+		assert.Equal(t, "g", added.Lines[0].Unit)
+		assert.Equal(t, 200, added.Lines[0].ToBuy)
+		assert.Equal(t, "pinch", added.Lines[1].Unit)
+		assert.Equal(t, 2, added.Lines[1].ToBuy)
+		assert.NotEqual(t, added.Lines[0].CartID, added.Lines[1].CartID)
+	}
+
+	quantities := make(map[string]int)
+	for _, line := range getCartLines(app) {
+		quantities[line.Unit] = line.Quantity
+	}
+	assert.Equal(t, map[string]int{"tsp": 1, "g": 200, "pinch": 2}, quantities)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-03-02&to=2026-03-02", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	quantities = make(map[string]int)
+	for _, line := range getCartLines(app) {
+		quantities[line.Unit] = line.Quantity
+	}
+	assert.Equal(t, map[string]int{"tsp": 1, "g": 400, "pinch": 4}, quantities)
+}
+
+func TestAddPlanToCart_RoundsInTheSmallerUnit(t *testing.T) {
+	setupTestDB()
+	app := setupCartFromPlanApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Scones", Servings: 1})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 300, Unit: "g"})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "breakfast", DishID: 1, Servings: 1})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 1, Unit: "kg", AddedBy: 1})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/cart/from-plan?from=2026-03-02&to=2026-03-02", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var added models.CartFromPlanResponse
+	json.NewDecoder(resp.Body).Decode(&added)
+	assert.Equal(t, []models.CartPlanLine{
+		{IngredientID: 1, IngredientName: "Flour", Needed: 300, ToBuy: 300, Unit: "g", CartID: added.Lines[0].CartID},
+	}, added.Lines)
+
+	cart := getCartLines(app)
+	if assert.Len(t, cart, 1) {
+		assert.Equal(t, 1300, cart[0].Quantity)
+		assert.Equal(t, "g", cart[0].Unit)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"foodapp/models"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -36,6 +37,14 @@ func setupCartApp() *fiber.App {
 		c.Locals("userID", uint(1))
 		return handlers.UpdateQuantityCart(c)
 	})
+	app.Post("/cart/add-dish", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return handlers.AddDishToCart(c)
+	})
+	app.Post("/cart/remove-dish", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return handlers.RemoveDishFromCart(c)
+	})
 	return app
 }
 
@@ -111,3 +120,155 @@ func TestRemoveAllIngredientsCart(t *testing.T) {
 	
 	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
 }
+
+func seedCartDish() {
+	database.DB.Create(&models.Dish{ID: 1, Name: "Omelette", Servings: 2})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Egg"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Milk"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 3, Unit: "pcs"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 100, Unit: "ml"})
+}
+
+func TestAddDishToCart_DishNotFound(t *testing.T) {
+	setupTestDB()
+	app := setupCartApp()
+
+	requestBody, _ := json.Marshal(models.CartAddDishRequest{DishID: 999})
+	request := httptest.NewRequest(http.MethodPost, "/cart/add-dish", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestAddDishToCart_MergesAndScales(t *testing.T) {
+	setupTestDB()
+	app := setupCartApp()
+	seedCartDish()
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 2})
+
+	requestBody, _ := json.Marshal(models.CartAddDishRequest{DishID: 1, Servings: 4})
+	request := httptest.NewRequest(http.MethodPost, "/cart/add-dish", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var eggs, milk models.Cart
+	database.DB.Where("user_id = ? AND ingredient_id = ?", 1, 1).First(&eggs)
+	database.DB.Where("user_id = ? AND ingredient_id = ?", 1, 2).First(&milk)
+	assert.Equal(t, 8, eggs.Quantity)
+	assert.Equal(t, 200, milk.Quantity)
+}
+
+func TestRemoveDishFromCart_SubtractsOnlyDishShare(t *testing.T) {
+	setupTestDB()
+	app := setupCartApp()
+	seedCartDish()
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 2})
+
+	requestBody, _ := json.Marshal(models.CartAddDishRequest{DishID: 1})
+	request := httptest.NewRequest(http.MethodPost, "/cart/add-dish", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	requestBody, _ = json.Marshal(models.CartRemoveDishRequest{DishID: 1})
+	request = httptest.NewRequest(http.MethodPost, "/cart/remove-dish", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var lines []models.Cart
+	database.DB.Where("user_id = ?", 1).Find(&lines)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, uint(1), lines[0].IngredientID)
+	assert.Equal(t, 2, lines[0].Quantity)
+
+	var contributions int64
+	database.DB.Model(&models.CartDishContribution{}).Count(&contributions)
+	assert.Equal(t, int64(0), contributions)
+}
+
+func TestRemoveDishFromCart_NotInCart(t *testing.T) {
+	setupTestDB()
+	app := setupCartApp()
+
+	requestBody, _ := json.Marshal(models.CartRemoveDishRequest{DishID: 1})
+	request := httptest.NewRequest(http.MethodPost, "/cart/remove-dish", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestAddDishToCart_ConvertsUnits(t *testing.T) {
+	setupTestDB()
+	app := setupCartApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Sugar"})
+	for id, need := range map[uint]models.DishIngredient{
+		1: {IngredientID: 1, Quantity: 500, Unit: "g"},
+		2: {IngredientID: 1, Quantity: 1, Unit: "kg"},
+		3: {IngredientID: 1, Quantity: 2, Unit: "pinch"},
+		4: {IngredientID: 2, Quantity: 1, Unit: "kg"},
+		5: {IngredientID: 2, Quantity: 250, Unit: "g"},
+	} {
+		database.DB.Create(&models.Dish{ID: id, Name: "Dish", Servings: 1})
+		need.DishID = id
+		database.DB.Create(&need)
+	}
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 2})
+
+	for _, dishID := range []uint{1, 2, 3, 4, 5} {
+		resp := sendJSON(app, http.MethodPost, "/cart/add-dish", models.CartAddDishRequest{DishID: dishID})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	lines := func() map[string]int {
+		var cart []models.Cart
+		database.DB.Where("user_id = ?", 1).Find(&cart)
+		quantities := make(map[string]int)
+		for _, line := range cart {
+			quantities[strconv.Itoa(int(line.IngredientID))+" "+line.Unit] = line.Quantity
+		}
+		return quantities
+	}
+	assert.Equal(t, map[string]int{"1 g": 1502, "1 pinch": 2, "2 g": 1250}, lines())
+
+	for _, dishID := range []uint{2, 4} {
+		resp := sendJSON(app, http.MethodPost, "/cart/remove-dish", models.CartRemoveDishRequest{DishID: dishID})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, map[string]int{"1 g": 502, "1 pinch": 2, "2 g": 250}, lines())
+}
+
+func TestAddDishToCart_FractionalAmountsInFinerUnits(t *testing.T) {
+	setupTestDB()
+	app := setupCartApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Salt"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Milk"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Bread", Servings: 1})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 0.5, Unit: "kg"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 1.5, Unit: "tsp"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 3, Quantity: 0.25, Unit: "l"})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 3, Quantity: 1, Unit: "l"})
+
+	resp := sendJSON(app, http.MethodPost, "/cart/add-dish", models.CartAddDishRequest{DishID: 1})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var cart []models.Cart
+	database.DB.Where("user_id = ?", 1).Order("ingredient_id").Find(&cart)
+	quantities := make(map[string]int)
+	for _, line := range cart {
+		quantities[strconv.Itoa(int(line.IngredientID))+" "+line.Unit] = line.Quantity
+	}
+	assert.Equal(t, map[string]int{"1 g": 500, "2 ml": 8, "3 ml": 1250}, quantities)
+
+	for i, want := range []int{500, 8, 250} {
+		var contribution models.CartDishContribution
+		database.DB.Where("cart_id = ? AND dish_id = ?", cart[i].ID, 1).First(&contribution)
+		assert.Equal(t, want, contribution.Quantity)
+	}
+}
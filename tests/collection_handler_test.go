@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCollectionApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/favorites-dishes/add", handlers.AddFavoriteDish)
+	app.Get("/favorites-dishes/get", handlers.GetUserFavoriteDishes)
+
+	collections := app.Group("/collections", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	collections.Get("/", handlers.GetCollections)
+	collections.Post("/", handlers.CreateCollection)
+	collections.Get("/:id", handlers.GetCollection)
+	collections.Put("/:id", handlers.RenameCollection)
+	collections.Delete("/:id", handlers.DeleteCollection)
+	collections.Put("/:id/order", handlers.ReorderCollection)
+	collections.Post("/:id/dishes", handlers.AddCollectionDish)
+	collections.Put("/:id/dishes/:dish_id", handlers.UpdateCollectionDish)
+	collections.Delete("/:id/dishes/:dish_id", handlers.RemoveCollectionDish)
+	return app
+}
+
+func sendJSON(app *fiber.App, method, url string, body interface{}) *http.Response {
+	requestBody, _ := json.Marshal(body)
+	request := httptest.NewRequest(method, url, bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	return resp
+}
+
+func seedCollectionDishes() {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Soup"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Stew"})
+	database.DB.Create(&models.Dish{ID: 3, Name: "Salad"})
+}
+
+func TestCollections_CreateRenameDelete(t *testing.T) {
+	setupTestDB()
+	app := setupCollectionApp()
+	seedCollectionDishes()
+
+	resp := sendJSON(app, http.MethodPost, "/collections", models.CollectionRequest{Name: " Weeknight "})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var created models.CollectionResponse
+	json.NewDecoder(resp.Body).Decode(&created)
+	assert.Equal(t, "Weeknight", created.Name)
+
+	resp = sendJSON(app, http.MethodPost, "/collections", models.CollectionRequest{Name: "weeknight"})
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	id := strconv.Itoa(int(created.ID))
+	resp = sendJSON(app, http.MethodPut, "/collections/"+id, models.CollectionRequest{Name: "Holidays"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/collections/"+id+"/dishes", models.CollectionDishRequest{DishID: 1})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	request := httptest.NewRequest(http.MethodDelete, "/collections/"+id, nil)
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var count int64
+	database.DB.Model(&models.FavoriteDish{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCollections_NotesAndOrdering(t *testing.T) {
+	setupTestDB()
+	app := setupCollectionApp()
+	seedCollectionDishes()
+
+	resp := sendJSON(app, http.MethodPost, "/collections", models.CollectionRequest{Name: "Weeknight"})
+	var created models.CollectionResponse
+	json.NewDecoder(resp.Body).Decode(&created)
+	id := strconv.Itoa(int(created.ID))
+
+	for _, dishID := range []uint{1, 2, 3} {
+		resp = sendJSON(app, http.MethodPost, "/collections/"+id+"/dishes", models.CollectionDishRequest{DishID: dishID})
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	}
+
+	resp = sendJSON(app, http.MethodPost, "/collections/"+id+"/dishes", models.CollectionDishRequest{DishID: 1})
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPut, "/collections/"+id+"/dishes/2", models.CollectionNoteRequest{Note: "double the garlic"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPut, "/collections/"+id+"/order", models.CollectionOrderRequest{DishIDs: []uint{3, 1}})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPut, "/collections/"+id+"/order", models.CollectionOrderRequest{DishIDs: []uint{3, 1, 2}})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var collection models.CollectionWithDishes
+	json.NewDecoder(resp.Body).Decode(&collection)
+	assert.Equal(t, int64(3), collection.DishCount)
+	assert.Len(t, collection.Dishes, 3)
+	assert.Equal(t, "Salad", collection.Dishes[0].Dish.Name)
+	assert.Equal(t, "Soup", collection.Dishes[1].Dish.Name)
+	assert.Equal(t, "Stew", collection.Dishes[2].Dish.Name)
+	assert.Equal(t, "double the garlic", collection.Dishes[2].Note)
+}
+
+func TestFavoriteDishes_DefaultCollection(t *testing.T) {
+	setupTestDB()
+	app := setupCollectionApp()
+	seedCollectionDishes()
+
+	resp := sendJSON(app, http.MethodPost, "/favorites-dishes/add", models.FavoriteDishRequest{UserID: 1, DishID: 1})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/collections", models.CollectionRequest{Name: "Holidays"})
+	var holidays models.CollectionResponse
+	json.NewDecoder(resp.Body).Decode(&holidays)
+	sendJSON(app, http.MethodPost, "/collections/"+strconv.Itoa(int(holidays.ID))+"/dishes", models.CollectionDishRequest{DishID: 2})
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/collections", nil))
+	var collections []models.CollectionResponse
+	json.NewDecoder(resp.Body).Decode(&collections)
+	assert.Len(t, collections, 2)
+	assert.True(t, collections[0].IsDefault)
+	assert.Equal(t, models.DefaultCollectionName, collections[0].Name)
+
+	request := httptest.NewRequest(http.MethodDelete, "/collections/"+strconv.Itoa(int(collections[0].ID)), nil)
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/favorites-dishes/get?email=test@example.com", nil))
+	var result map[string][]models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result["favorite_dishes"], 1)
+	assert.Equal(t, "Soup", result["favorite_dishes"][0].Dish.Name)
+}
+
+func TestMigrateFavoriteCollections(t *testing.T) {
+	setupTestDB()
+	seedCollectionDishes()
+
+	database.DB.Create(&models.FavoriteDish{UserID: 1, DishID: 1})
+	database.DB.Create(&models.FavoriteDish{UserID: 1, DishID: 2})
+
+	assert.NoError(t, database.MigrateFavoriteCollections(database.DB))
+	assert.NoError(t, database.MigrateFavoriteCollections(database.DB))
+
+	var collections []models.FavoriteCollection
+	database.DB.Find(&collections)
+	assert.Len(t, collections, 1)
+	assert.True(t, collections[0].IsDefault)
+
+	var count int64
+	database.DB.Model(&models.FavoriteDish{}).Where("collection_id = ?", collections[0].ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
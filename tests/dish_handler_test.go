@@ -112,3 +112,25 @@ func TestCreateDish_Success(t *testing.T) {
 	resp, _ := app.Test(request)
 	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
 }
+
+func TestGetAllDishes_ScaledByServings(t *testing.T) {
+	setupTestDB()
+	app := setupDishApp()
+
+	dish := models.Dish{ID: 1, Name: "Test Dish", Calories: 300, Servings: 2}
+	database.DB.Create(&dish)
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Rice"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 150, Unit: "g"})
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes?servings=6", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body, 1)
+	assert.Equal(t, 6, body[0].Servings)
+	assert.Equal(t, 450.0, body[0].Ingredients[0].Quantity)
+	assert.Equal(t, 300.0, body[0].PerServing.Calories)
+	assert.Equal(t, 1800.0, body[0].Total.Calories)
+}
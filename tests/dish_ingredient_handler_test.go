@@ -81,3 +81,38 @@ func TestAddIngredientToDishes_DishNotFound(t *testing.T) {
 	resp, _ := app.Test(request)
 	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
 }
+
+func TestGetDishIngredients_ScaledByServings(t *testing.T) {
+	setupTestDB()
+	app := setupDishIngredientApp()
+
+	dish := models.Dish{ID: 1, Name: "Test Dish", Servings: 4}
+	database.DB.Create(&dish)
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Egg"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 500, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 3, Unit: "pcs"})
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes-ingredients/1?servings=2", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body []models.DishIngredientResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body, 2)
+	assert.Equal(t, 250.0, body[0].Quantity)
+	assert.Equal(t, 2.0, body[1].Quantity)
+}
+
+func TestGetDishIngredients_InvalidServings(t *testing.T) {
+	setupTestDB()
+	app := setupDishIngredientApp()
+
+	dish := models.Dish{ID: 1, Name: "Test Dish"}
+	database.DB.Create(&dish)
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes-ingredients/1?servings=0", nil)
+	resp, _ := app.Test(request)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"foodapp/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDishStepApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/dishes/:id/steps", handlers.GetDishSteps)
+	app.Post("/dishes/:id/steps", handlers.AddDishStep)
+	app.Put("/dishes/:id/steps/:step_id", handlers.UpdateDishStep)
+	app.Delete("/dishes/:id/steps/:step_id", handlers.DeleteDishStep)
+	return app
+}
+
+func getDishSteps(app *fiber.App, dishID string) []models.DishStepResponse {
+	request := httptest.NewRequest(http.MethodGet, "/dishes/"+dishID+"/steps", nil)
+	resp, _ := app.Test(request)
+
+	var steps []models.DishStepResponse
+	json.NewDecoder(resp.Body).Decode(&steps)
+	return steps
+}
+
+func TestGetDishSteps_DishNotFound(t *testing.T) {
+	setupTestDB()
+	app := setupDishStepApp()
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes/999/steps", nil)
+	resp, _ := app.Test(request)
+
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestAddDishStep_InsertAtPosition(t *testing.T) {
+	setupTestDB()
+	app := setupDishStepApp()
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Pasta"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 7, Quantity: 200})
+	database.DB.Create(&models.DishStep{DishID: 1, Position: 1, Text: "Boil water"})
+	database.DB.Create(&models.DishStep{DishID: 1, Position: 2, Text: "Drain"})
+
+	requestBody, _ := json.Marshal(models.DishStepRequest{
+		Position:        2,
+		Text:            "Cook pasta",
+		DurationSeconds: 600,
+		IngredientIDs:   []uint{7},
+	})
+	request := httptest.NewRequest(http.MethodPost, "/dishes/1/steps", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	steps := getDishSteps(app, "1")
+	assert.Len(t, steps, 3)
+	assert.Equal(t, "Cook pasta", steps[1].Text)
+	assert.Equal(t, 600, steps[1].DurationSeconds)
+	assert.Equal(t, []uint{7}, steps[1].IngredientIDs)
+	assert.Equal(t, "Drain", steps[2].Text)
+	assert.Equal(t, 3, steps[2].Position)
+}
+
+func TestAddDishStep_UnknownIngredient(t *testing.T) {
+	setupTestDB()
+	app := setupDishStepApp()
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Pasta"})
+
+	requestBody, _ := json.Marshal(models.DishStepRequest{Text: "Cook", IngredientIDs: []uint{42}})
+	request := httptest.NewRequest(http.MethodPost, "/dishes/1/steps", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestUpdateAndDeleteDishStep_ReordersSteps(t *testing.T) {
+	setupTestDB()
+	app := setupDishStepApp()
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Pasta"})
+	database.DB.Create(&models.DishStep{ID: 1, DishID: 1, Position: 1, Text: "A"})
+	database.DB.Create(&models.DishStep{ID: 2, DishID: 1, Position: 2, Text: "B"})
+	database.DB.Create(&models.DishStep{ID: 3, DishID: 1, Position: 3, Text: "C"})
+
+	requestBody, _ := json.Marshal(models.DishStepRequest{Position: 1, Text: "C first"})
+	request := httptest.NewRequest(http.MethodPut, "/dishes/1/steps/3", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	steps := getDishSteps(app, "1")
+	assert.Equal(t, []string{"C first", "A", "B"}, []string{steps[0].Text, steps[1].Text, steps[2].Text})
+
+	request = httptest.NewRequest(http.MethodDelete, "/dishes/1/steps/1", nil)
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	steps = getDishSteps(app, "1")
+	assert.Len(t, steps, 2)
+	assert.Equal(t, "B", steps[1].Text)
+	assert.Equal(t, 2, steps[1].Position)
+}
+
+func TestMigrateDishSteps_SplitsInstruction(t *testing.T) {
+	setupTestDB()
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Tea", Instruction: "1. Boil water\n2) Add tea\n\nStep 3: Wait"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Toast", Instruction: "1. Slice bread 2. Toast it"})
+
+	assert.NoError(t, database.MigrateDishSteps(database.DB))
+	assert.NoError(t, database.MigrateDishSteps(database.DB))
+
+	var steps []models.DishStep
+	database.DB.Where("dish_id = ?", 1).Order("position").Find(&steps)
+	assert.Len(t, steps, 3)
+	assert.Equal(t, "Boil water", steps[0].Text)
+	assert.Equal(t, "Wait", steps[2].Text)
+
+	assert.Equal(t, []string{"Slice bread", "Toast it"}, utils.SplitInstruction("1. Slice bread 2. Toast it"))
+}
+
+func TestMigrateDishSteps_RunsOnce(t *testing.T) {
+	setupTestDB()
+	app := setupDishStepApp()
+	database.DB.Create(&models.Dish{ID: 1, Name: "Tea", Instruction: "Boil water\nAdd tea"})
+
+	assert.NoError(t, database.MigrateDishSteps(database.DB))
+	steps := getDishSteps(app, "1")
+	assert.Len(t, steps, 2)
+
+	for _, step := range steps {
+		request := httptest.NewRequest(http.MethodDelete, "/dishes/1/steps/"+strconv.Itoa(int(step.ID)), nil)
+		resp, _ := app.Test(request)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+	database.DB.Create(&models.Dish{ID: 2, Name: "Coffee", Instruction: "Grind beans"})
+
+	assert.NoError(t, database.MigrateDishSteps(database.DB))
+	assert.Empty(t, getDishSteps(app, "1"))
+	assert.Empty(t, getDishSteps(app, "2"))
+}
+
+func TestCreateDish_AddsStepsFromInstruction(t *testing.T) {
+	setupTestDB()
+	app := setupDishStepApp()
+	app.Post("/dishes/create", handlers.CreateDish)
+
+	requestBody, _ := json.Marshal(models.CreateDishRequest{
+		Name:        "Tea",
+		Instruction: "1. Boil water\n2. Add tea",
+	})
+	request := httptest.NewRequest(http.MethodPost, "/dishes/create", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var dish models.DishResponse
+	json.NewDecoder(resp.Body).Decode(&dish)
+	steps := getDishSteps(app, strconv.Itoa(int(dish.ID)))
+	if assert.Len(t, steps, 2) {
+		assert.Equal(t, "Boil water", steps[0].Text)
+		assert.Equal(t, 2, steps[1].Position)
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupGoalApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/users/profile/goals", handlers.GetNutritionGoals)
+	app.Put("/users/profile/goals", handlers.SetNutritionGoal)
+	app.Get("/statistics/progress", handlers.GetProgress)
+	return app
+}
+
+func getProgress(t *testing.T, app *fiber.App, query string) models.ProgressResponse {
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/statistics/progress?"+query, nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var progress models.ProgressResponse
+	json.NewDecoder(resp.Body).Decode(&progress)
+	return progress
+}
+
+func noon(date string) time.Time {
+	return mustPlanDate(date).Add(12 * time.Hour)
+}
+
+func TestSetNutritionGoal(t *testing.T) {
+	setupTestDB()
+	app := setupGoalApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+
+	for _, req := range []models.NutritionGoalRequest{
+		{Targets: models.Nutrition{Proteins: 100}},
+		{Targets: models.Nutrition{Calories: 2000, Fats: -5}},
+		{EffectiveFrom: "1 March", Targets: models.Nutrition{Calories: 2000}},
+	} {
+		resp := sendJSON(app, http.MethodPut, "/users/profile/goals", req)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "%+v", req)
+	}
+
+	resp := sendJSON(app, http.MethodPut, "/users/profile/goals", models.NutritionGoalRequest{
+		EffectiveFrom: "2026-03-01",
+		Targets:       models.Nutrition{Calories: 2000, Proteins: 100},
+	})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var first models.NutritionGoalResponse
+	json.NewDecoder(resp.Body).Decode(&first)
+	assert.Equal(t, "2026-03-01", first.EffectiveFrom)
+
+	resp = sendJSON(app, http.MethodPut, "/users/profile/goals", models.NutritionGoalRequest{
+		EffectiveFrom: "2026-03-01",
+		Targets:       models.Nutrition{Calories: 1800, Proteins: 120},
+	})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var replaced models.NutritionGoalResponse
+	json.NewDecoder(resp.Body).Decode(&replaced)
+	assert.Equal(t, first.ID, replaced.ID)
+	assert.Equal(t, models.Nutrition{Calories: 1800, Proteins: 120}, replaced.Targets)
+
+	today := time.Now().UTC().Format(models.MealPlanDateLayout)
+	resp = sendJSON(app, http.MethodPut, "/users/profile/goals", models.NutritionGoalRequest{Targets: models.Nutrition{Calories: 2200}})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = sendJSON(app, http.MethodPut, "/users/profile/goals", models.NutritionGoalRequest{
+		EffectiveFrom: time.Now().UTC().AddDate(0, 0, 30).Format(models.MealPlanDateLayout),
+		Targets:       models.Nutrition{Calories: 2500},
+	})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/users/profile/goals", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var goals models.NutritionGoalsResponse
+	json.NewDecoder(resp.Body).Decode(&goals)
+	assert.Len(t, goals.Goals, 3)
+	assert.Equal(t, "2026-03-01", goals.Goals[0].EffectiveFrom)
+	if assert.NotNil(t, goals.Current) {
+		assert.Equal(t, today, goals.Current.EffectiveFrom)
+		assert.Equal(t, 2200.0, goals.Current.Targets.Calories)
+	}
+}
+
+func TestGetProgress_StreaksAndDeltas(t *testing.T) {
+	setupTestDB()
+	app := setupGoalApp()
+	seedDiary("")
+	database.DB.Create(&models.NutritionGoal{UserID: 1, EffectiveFrom: mustPlanDate("2026-03-01"), Calories: 1000})
+	database.DB.Create(&models.NutritionGoal{UserID: 1, EffectiveFrom: mustPlanDate("2026-03-05"), Calories: 700, Proteins: 60})
+	database.DB.Create(&models.NutritionGoal{UserID: 2, EffectiveFrom: mustPlanDate("2026-02-01"), Calories: 3000})
+
+	for _, day := range []string{"2026-03-01", "2026-03-02", "2026-03-04"} {
+		eat(1, 1, noon(day))
+		eat(1, 2, noon(day))
+	}
+	eat(1, 2, noon("2026-03-03"))
+	eat(1, 2, noon("2026-03-05"))
+	eat(1, 1, noon("2026-02-28"))
+
+	progress := getProgress(t, app, "from=2026-02-28&to=2026-03-06")
+	assert.Equal(t, "UTC", progress.Timezone)
+	assert.Equal(t, 10.0, progress.TolerancePercent)
+	assert.Len(t, progress.Days, 7)
+
+	assert.Equal(t, 1, progress.Days[0].Dishes)
+	assert.Nil(t, progress.Days[0].Goal)
+	assert.False(t, progress.Days[0].WithinTarget)
+
+	within := []bool{}
+	for _, day := range progress.Days[1:] {
+		within = append(within, day.WithinTarget)
+	}
+	assert.Equal(t, []bool{true, true, false, true, true, false}, within)
+	assert.Equal(t, &models.Nutrition{Calories: -300}, progress.Days[3].Delta)
+	assert.Equal(t, &models.Nutrition{Calories: 700, Proteins: 60}, progress.Days[5].Goal)
+	assert.Equal(t, &models.Nutrition{Calories: -700, Proteins: -60}, progress.Days[6].Delta)
+
+	assert.Equal(t, 6, progress.DaysWithGoal)
+	assert.Equal(t, 4, progress.DaysWithinTarget)
+	assert.Equal(t, 2, progress.LongestStreak)
+	assert.Zero(t, progress.CurrentStreak)
+	assert.Equal(t, &models.Nutrition{Calories: -166.7, Proteins: -10}, progress.AverageDelta)
+
+	progress = getProgress(t, app, "from=2026-03-01&to=2026-03-05&tolerance=50")
+	assert.Equal(t, 5, progress.LongestStreak)
+	assert.Equal(t, 5, progress.CurrentStreak)
+
+	for _, query := range []string{"tolerance=-1", "tolerance=101", "from=2026-03-06&to=2026-03-01"} {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/statistics/progress?"+query, nil))
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestGetProgress_TodayDoesNotBreakStreak(t *testing.T) {
+	setupTestDB()
+	app := setupGoalApp()
+	seedDiary("")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	database.DB.Create(&models.NutritionGoal{UserID: 1, EffectiveFrom: today.AddDate(0, 0, -10), Calories: 1000})
+	for _, days := range []int{-2, -1} {
+		eat(1, 1, today.AddDate(0, 0, days).Add(12*time.Hour))
+		eat(1, 2, today.AddDate(0, 0, days).Add(12*time.Hour))
+	}
+
+	progress := getProgress(t, app, "")
+	assert.Len(t, progress.Days, 7)
+	assert.Equal(t, today.Format(models.MealPlanDateLayout), progress.To)
+	assert.False(t, progress.Days[6].WithinTarget)
+	assert.Equal(t, 2, progress.CurrentStreak)
+
+	eat(1, 1, today)
+	eat(1, 2, today)
+	progress = getProgress(t, app, "")
+	assert.Equal(t, 3, progress.CurrentStreak)
+	assert.Equal(t, 3, progress.LongestStreak)
+}
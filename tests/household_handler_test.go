@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHouseholdApp() *fiber.App {
+	app := fiber.New()
+	as := app.Group("/as/:user_id", func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return c.Next()
+	})
+	as.Post("/households", handlers.CreateHousehold)
+	as.Get("/households", handlers.GetHouseholds)
+	as.Put("/households/active", handlers.SetActiveHousehold)
+	as.Get("/households/invites", handlers.GetHouseholdInvites)
+	as.Post("/households/invites/:invite_id/accept", handlers.AcceptHouseholdInvite)
+	as.Post("/households/:id/invites", handlers.InviteToHousehold)
+	as.Delete("/households/:id/members/:member_id", handlers.RemoveHouseholdMember)
+	as.Delete("/households/:id", handlers.DeleteHousehold)
+
+	as.Post("/cart/add-ingredients", handlers.AddIngredientsToCart)
+	as.Get("/cart/get", handlers.GetUserCart)
+	as.Post("/cart/remove-ingredients", handlers.RemoveIngredientsCart)
+	return app
+}
+
+func seedHousehold(t *testing.T, app *fiber.App) models.HouseholdResponse {
+	database.DB.Create(&models.User{ID: 1, Email: "ana@example.com", UserName: "ana"})
+	database.DB.Create(&models.User{ID: 2, Email: "luka@example.com", UserName: "luka"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Bread"})
+
+	resp := sendJSON(app, http.MethodPost, "/as/1/households", models.HouseholdRequest{Name: "Home"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var household models.HouseholdResponse
+	json.NewDecoder(resp.Body).Decode(&household)
+
+	resp = sendJSON(app, http.MethodPost, "/as/1/households/"+strconv.Itoa(int(household.ID))+"/invites", models.HouseholdInviteRequest{Email: "luka@example.com"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var invite models.HouseholdInvite
+	json.NewDecoder(resp.Body).Decode(&invite)
+
+	resp = sendJSON(app, http.MethodPost, "/as/2/households/invites/"+strconv.Itoa(int(invite.ID))+"/accept", nil)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	json.NewDecoder(resp.Body).Decode(&household)
+	return household
+}
+
+func getCart(app *fiber.App, userID string) []models.CartResponse {
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/as/"+userID+"/cart/get", nil))
+	var cart []models.CartResponse
+	json.NewDecoder(resp.Body).Decode(&cart)
+	return cart
+}
+
+func TestHousehold_InviteAndAccept(t *testing.T) {
+	setupTestDB()
+	app := setupHouseholdApp()
+	household := seedHousehold(t, app)
+
+	assert.Len(t, household.Members, 2)
+	assert.Equal(t, models.HouseholdRoleOwner, household.Members[0].Role)
+	assert.Equal(t, "luka", household.Members[1].UserName)
+
+	resp := sendJSON(app, http.MethodPost, "/as/1/households/"+strconv.Itoa(int(household.ID))+"/invites", models.HouseholdInviteRequest{Email: "luka@example.com"})
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPut, "/as/3/households/active", models.ActiveHouseholdRequest{HouseholdID: &household.ID})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	id := strconv.Itoa(int(household.ID))
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/as/2/households/"+id+"/members/1", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/as/2/households/"+id+"/members/2", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/2/households", nil))
+	var households []models.HouseholdResponse
+	json.NewDecoder(resp.Body).Decode(&households)
+	assert.Len(t, households, 0)
+}
+
+func TestHousehold_SharedCart(t *testing.T) {
+	setupTestDB()
+	app := setupHouseholdApp()
+	household := seedHousehold(t, app)
+
+	sendJSON(app, http.MethodPost, "/as/1/cart/add-ingredients", models.CartRequest{IngredientID: 2, Quantity: 1})
+
+	for _, userID := range []string{"1", "2"} {
+		resp := sendJSON(app, http.MethodPut, "/as/"+userID+"/households/active", models.ActiveHouseholdRequest{HouseholdID: &household.ID})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp := sendJSON(app, http.MethodPost, "/as/1/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 2})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = sendJSON(app, http.MethodPost, "/as/2/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 1})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	cart := getCart(app, "2")
+	assert.Len(t, cart, 1)
+	assert.Equal(t, 3, cart[0].Quantity)
+	assert.Equal(t, uint(1), cart[0].AddedBy)
+	assert.Equal(t, "ana", cart[0].AddedByName)
+	assert.Equal(t, household.ID, *cart[0].HouseholdID)
+
+	resp = sendJSON(app, http.MethodPost, "/as/2/cart/remove-ingredients", models.CartRemoveIngredientRequest{IngredientID: 1})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Len(t, getCart(app, "1"), 0)
+
+	resp = sendJSON(app, http.MethodPut, "/as/1/households/active", models.ActiveHouseholdRequest{})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	personal := getCart(app, "1")
+	assert.Len(t, personal, 1)
+	assert.Equal(t, "Bread", personal[0].Ingredient.Name)
+}
+
+func TestHousehold_DeleteFallsBackToPersonalCart(t *testing.T) {
+	setupTestDB()
+	app := setupHouseholdApp()
+	household := seedHousehold(t, app)
+
+	sendJSON(app, http.MethodPut, "/as/2/households/active", models.ActiveHouseholdRequest{HouseholdID: &household.ID})
+	sendJSON(app, http.MethodPost, "/as/2/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 2})
+
+	request := httptest.NewRequest(http.MethodDelete, "/as/2/households/"+strconv.Itoa(int(household.ID)), nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	request = httptest.NewRequest(http.MethodDelete, "/as/1/households/"+strconv.Itoa(int(household.ID)), nil)
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var user models.User
+	database.DB.First(&user, 2)
+	assert.Nil(t, user.ActiveHouseholdID)
+	assert.Len(t, getCart(app, "2"), 0)
+
+	var count int64
+	database.DB.Model(&models.Cart{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
+	"foodapp/database"
 	"foodapp/handlers"
 	"foodapp/models"
 	"net/http"
@@ -44,3 +45,91 @@ func TestAddIngredient_Success(t *testing.T) {
 	resp, _ := app.Test(request)
 	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
 }
+
+func setupIngredientCatalogueApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/ingredients", handlers.GetIngredients)
+	app.Get("/ingredients/search", handlers.SearchIngredients)
+	app.Get("/ingredients/:id", handlers.GetIngredient)
+	app.Put("/ingredients/:id", handlers.UpdateIngredient)
+	app.Delete("/ingredients/:id", handlers.DeleteIngredient)
+	return app
+}
+
+func TestGetIngredients_Paginated(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientCatalogueApp()
+
+	for _, name := range []string{"Basil", "Apple", "Carrot"} {
+		database.DB.Create(&models.Ingredient{Name: name})
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/ingredients?page=2&page_size=2", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body models.IngredientListResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, int64(3), body.Total)
+	assert.Len(t, body.Items, 1)
+	assert.Equal(t, "Carrot", body.Items[0].Name)
+}
+
+func TestSearchIngredients_Prefix(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientCatalogueApp()
+
+	for _, name := range []string{"Tomato", "tofu", "Potato"} {
+		database.DB.Create(&models.Ingredient{Name: name})
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/ingredients/search?q=To", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body []models.IngredientResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body, 2)
+}
+
+func TestUpdateIngredient_Success(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientCatalogueApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Tomatoe"})
+
+	requestBody, _ := json.Marshal(models.IngredientRequest{Name: "Tomato"})
+	request := httptest.NewRequest(http.MethodPut, "/ingredients/1", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "/ingredients/1", nil)
+	resp, _ = app.Test(request)
+
+	var body models.IngredientResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "Tomato", body.Name)
+}
+
+func TestDeleteIngredient_InUseRequiresForce(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientCatalogueApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Salt"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 5})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 1})
+
+	request := httptest.NewRequest(http.MethodDelete, "/ingredients/1", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	request = httptest.NewRequest(http.MethodDelete, "/ingredients/1?force=true", nil)
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var remaining int64
+	database.DB.Model(&models.DishIngredient{}).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+	database.DB.Model(&models.Cart{}).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+}
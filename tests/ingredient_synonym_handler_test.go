@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/middleware"
+	"foodapp/models"
+	"foodapp/service"
+	"foodapp/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupIngredientSynonymApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/ingredients/add", handlers.AddIngredient)
+	app.Get("/ingredients/search", handlers.SearchIngredients)
+	app.Get("/ingredients/duplicates", handlers.GetDuplicateIngredients)
+	app.Post("/ingredients/merge", handlers.MergeIngredients)
+	app.Get("/ingredients/:id/synonyms", handlers.GetIngredientSynonyms)
+	app.Post("/ingredients/:id/synonyms", handlers.AddIngredientSynonym)
+	return app
+}
+
+func TestNormalizeIngredientName(t *testing.T) {
+	assert.Equal(t, "tomato", utils.NormalizeIngredientName(" Tomatoes "))
+	assert.Equal(t, "cherry", utils.NormalizeIngredientName("Cherries"))
+	assert.Equal(t, "green bean", utils.NormalizeIngredientName("Green  Beans"))
+	assert.Equal(t, "couscous", utils.NormalizeIngredientName("Couscous"))
+	assert.Equal(t, "glass", utils.NormalizeIngredientName("glass"))
+}
+
+func TestAddIngredient_RejectsNormalizedDuplicate(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientSynonymApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Tomato", NormalizedName: "tomato"})
+
+	for _, name := range []string{"tomato", "Tomatoes"} {
+		requestBody, _ := json.Marshal(models.IngredientRequest{Name: name})
+		request := httptest.NewRequest(http.MethodPost, "/ingredients/add", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+
+		resp, _ := app.Test(request)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestAddIngredientSynonym_UsedBySearchAndUniqueness(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientSynonymApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Tomato", NormalizedName: "tomato"})
+
+	requestBody, _ := json.Marshal(models.IngredientSynonymRequest{Name: "Pomidor", Language: "PL"})
+	request := httptest.NewRequest(http.MethodPost, "/ingredients/1/synonyms", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "/ingredients/search?q=pomi", nil)
+	resp, _ = app.Test(request)
+	var found []models.IngredientResponse
+	json.NewDecoder(resp.Body).Decode(&found)
+	assert.Len(t, found, 1)
+	assert.Equal(t, "Tomato", found[0].Name)
+
+	requestBody, _ = json.Marshal(models.IngredientRequest{Name: "pomidory"})
+	request = httptest.NewRequest(http.MethodPost, "/ingredients/add", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	requestBody, _ = json.Marshal(models.IngredientRequest{Name: "POMIDOR"})
+	request = httptest.NewRequest(http.MethodPost, "/ingredients/add", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestGetDuplicateIngredients(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientSynonymApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Tomato", NormalizedName: "tomato"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "tomatoes"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Onion", NormalizedName: "onion"})
+
+	request := httptest.NewRequest(http.MethodGet, "/ingredients/duplicates", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var groups []models.DuplicateIngredientGroup
+	json.NewDecoder(resp.Body).Decode(&groups)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "tomato", groups[0].NormalizedName)
+	assert.Len(t, groups[0].Ingredients, 2)
+}
+
+func TestMergeIngredients_RepointsDishesAndCarts(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientSynonymApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Tomato", NormalizedName: "tomato"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Tomatoes", NormalizedName: "tomato"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 100, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 50, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 2, Quantity: 3, Unit: "pcs"})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 2})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 2, Quantity: 3})
+	database.DB.Create(&models.IngredientAllergen{IngredientID: 2, Allergen: "sulphites"})
+
+	requestBody, _ := json.Marshal(models.IngredientMergeRequest{CanonicalID: 1, DuplicateIDs: []uint{2}})
+	request := httptest.NewRequest(http.MethodPost, "/ingredients/merge", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var dishIngredients []models.DishIngredient
+	database.DB.Order("dish_id").Find(&dishIngredients)
+	assert.Len(t, dishIngredients, 2)
+	assert.Equal(t, 150.0, dishIngredients[0].Quantity)
+	assert.Equal(t, uint(1), dishIngredients[1].IngredientID)
+
+	var lines []models.Cart
+	database.DB.Find(&lines)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, 5, lines[0].Quantity)
+
+	var count int64
+	database.DB.Model(&models.Ingredient{}).Where("id = ?", 2).Count(&count)
+	assert.Equal(t, int64(0), count)
+	database.DB.Model(&models.IngredientAllergen{}).Where("ingredient_id = ?", 1).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMergeIngredients_CartLinesByUnit(t *testing.T) {
+	setupTestDB()
+	app := setupIngredientSynonymApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Tomatoes", NormalizedName: "tomato"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Tomato", NormalizedName: "tomato"})
+	database.DB.Create(&models.Cart{ID: 1, UserID: 1, IngredientID: 1, Quantity: 1, Unit: "kg"})
+	database.DB.Create(&models.Cart{ID: 2, UserID: 1, IngredientID: 2, Quantity: 300, Unit: "g"})
+	database.DB.Create(&models.Cart{ID: 3, UserID: 1, IngredientID: 2, Quantity: 2, Unit: "cans"})
+	database.DB.Create(&models.CartDishContribution{CartID: 1, UserID: 1, DishID: 7, Quantity: 1})
+
+	events, cancel := service.CartEvents.Subscribe("user:1")
+	defer cancel()
+
+	resp := sendJSON(app, http.MethodPost, "/ingredients/merge", models.IngredientMergeRequest{CanonicalID: 1, DuplicateIDs: []uint{2}})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var lines []models.Cart
+	database.DB.Order("id").Find(&lines)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, 1300, lines[0].Quantity)
+		assert.Equal(t, "g", lines[0].Unit)
+		assert.Equal(t, uint(1), lines[1].IngredientID)
+		assert.Equal(t, "cans", lines[1].Unit)
+	}
+
+	var contribution models.CartDishContribution
+	database.DB.Where("dish_id = ?", 7).First(&contribution)
+	assert.Equal(t, 1000, contribution.Quantity)
+
+	removed := nextCartEvent(t, events)
+	assert.Equal(t, models.CartEventLineRemoved, removed.Type)
+	assert.Equal(t, uint(2), removed.LineID)
+	updated := nextCartEvent(t, events)
+	assert.Equal(t, models.CartEventLineUpdated, updated.Type)
+	assert.Equal(t, uint(1), updated.LineID)
+	assert.Equal(t, 1300, updated.Quantity)
+	moved := nextCartEvent(t, events)
+	assert.Equal(t, uint(3), moved.LineID)
+	assert.Equal(t, uint(1), moved.IngredientID)
+}
+
+func TestRoleRequired_RejectsNonAdmin(t *testing.T) {
+	setupTestDB()
+	database.DB.Create(&models.User{ID: 1, Email: "user@example.com", Role: models.RoleUser})
+	database.DB.Create(&models.User{ID: 2, Email: "admin@example.com", Role: models.RoleAdmin})
+
+	app := fiber.New()
+	app.Get("/admin/:user_id", func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return c.Next()
+	}, middleware.RoleRequired(models.RoleAdmin), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/admin/1", nil))
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/admin/2", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"foodapp/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMealPlanFeedApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/plans/feed.ics", handlers.GetMealPlanFeed)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/plans/feed", handlers.GetMealPlanFeedURL)
+	app.Delete("/plans/feed", handlers.RevokeMealPlanFeed)
+	app.Put("/plans/:id", handlers.UpdateMealPlanEntry)
+	return app
+}
+
+func getFeed(t *testing.T, app *fiber.App, feedURL string) (int, string) {
+	parsed, err := url.Parse(feedURL)
+	assert.NoError(t, err)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, parsed.RequestURI(), nil))
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestMealPlanFeed(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanFeedApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Chili, extra hot", PreparationTime: 90})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Toast"})
+
+	day := *pantryDate(2)
+	database.DB.Create(&models.MealPlanEntry{ID: 1, UserID: 1, Date: day, Slot: "dinner", DishID: 1, Servings: 4})
+	database.DB.Create(&models.MealPlanEntry{ID: 2, UserID: 1, Date: day.AddDate(0, 0, 1), Slot: "breakfast", DishID: 2, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{ID: 3, UserID: 1, Date: day.AddDate(0, 0, 200), Slot: "lunch", DishID: 2, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{ID: 4, UserID: 2, Date: day, Slot: "lunch", DishID: 2, Servings: 1})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/plans/feed", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var feed models.MealPlanFeedResponse
+	json.NewDecoder(resp.Body).Decode(&feed)
+	assert.Contains(t, feed.URL, "/plans/feed.ics?token=")
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/plans/feed", nil))
+	var again models.MealPlanFeedResponse
+	json.NewDecoder(resp.Body).Decode(&again)
+	assert.Equal(t, feed.URL, again.URL)
+
+	status, body := getFeed(t, app, feed.URL)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"))
+	assert.Contains(t, body, "UID:meal-plan-1@foodapp\r\n")
+	assert.Contains(t, body, "DTSTART:"+day.Format("20060102")+"T190000\r\n")
+	assert.Contains(t, body, "DURATION:PT1H30M\r\n")
+	assert.Contains(t, body, `SUMMARY:Chili\, extra hot`+"\r\n")
+	assert.Contains(t, body, "DESCRIPTION:Dinner\\, 4 servings\r\n")
+	assert.Contains(t, body, "/dishes-ingredients/1?servings=4\r\n")
+	assert.Contains(t, body, "DTSTART:"+day.AddDate(0, 0, 1).Format("20060102")+"T080000\r\nDURATION:PT30M\r\n")
+
+	resp = sendJSON(app, http.MethodPut, "/plans/1", models.MealPlanRequest{Date: day.Format(models.MealPlanDateLayout), Slot: "lunch", DishID: 1, Servings: 2})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	_, body = getFeed(t, app, feed.URL)
+	assert.Equal(t, 1, strings.Count(body, "UID:meal-plan-1@foodapp"))
+	assert.Contains(t, body, "DTSTART:"+day.Format("20060102")+"T123000\r\n")
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/plans/feed", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	status, _ = getFeed(t, app, feed.URL)
+	assert.Equal(t, fiber.StatusNotFound, status)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/plans/feed.ics", nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestICalendar_FoldsLongLines(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	calendar := string(utils.ICalendar("Plan", []utils.ICalEvent{{
+		UID:      "meal-plan-1@foodapp",
+		Summary:  strings.Repeat("Crème brûlée ", 10),
+		Start:    time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC),
+		Duration: 45 * time.Minute,
+		Modified: modified,
+	}}))
+
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+	assert.Contains(t, calendar, "DTSTAMP:20260301T090000Z\r\n")
+	assert.Contains(t, calendar, "DURATION:PT45M\r\n")
+
+	unfolded := strings.ReplaceAll(calendar, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("Crème brûlée ", 10)+"\r\n")
+}
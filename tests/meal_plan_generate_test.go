@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMealPlanGenerateApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/plans", handlers.GetMealPlan)
+	app.Post("/plans/generate", handlers.GenerateMealPlan)
+	return app
+}
+
+func seedGeneratorDishes() {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	for i, calories := range []int{350, 420, 480, 550, 600, 650, 700, 760} {
+		database.DB.Create(&models.Dish{
+			ID:              uint(i + 1),
+			Name:            "Dish",
+			PreparationTime: 20,
+			Calories:        calories,
+			Proteins:        calories / 20,
+			Fats:            calories / 40,
+			Carbs:           calories / 8,
+		})
+	}
+
+	database.DB.Create(&models.Dish{ID: 9, Name: "Vindaloo", PreparationTime: 30, Calories: 600})
+	database.DB.Create(&models.Tag{ID: 1, Name: "spicy"})
+	database.DB.Create(&models.DishTag{DishID: 9, TagID: 1})
+
+	database.DB.Create(&models.Dish{ID: 10, Name: "Satay", PreparationTime: 30, Calories: 600})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Peanuts"})
+	database.DB.Create(&models.IngredientAllergen{IngredientID: 1, Allergen: "peanuts"})
+	database.DB.Create(&models.DishIngredient{DishID: 10, IngredientID: 1, Quantity: 100, Unit: "g"})
+
+	database.DB.Create(&models.Dish{ID: 11, Name: "Roast", PreparationTime: 120, Calories: 600})
+	database.DB.Create(&models.Dish{ID: 12, Name: "Water", PreparationTime: 1})
+}
+
+func generatePlan(app *fiber.App, req models.MealPlanGenerateRequest) (int, models.MealPlanGenerateResponse) {
+	resp := sendJSON(app, http.MethodPost, "/plans/generate", req)
+	var generated models.MealPlanGenerateResponse
+	json.NewDecoder(resp.Body).Decode(&generated)
+	return resp.StatusCode, generated
+}
+
+func TestGenerateMealPlan_DeterministicWithinConstraints(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanGenerateApp()
+	seedGeneratorDishes()
+
+	seed := int64(42)
+	req := models.MealPlanGenerateRequest{
+		From:             "2026-03-02",
+		To:               "2026-03-03",
+		Targets:          models.Nutrition{Calories: 1800, Proteins: 90},
+		ExcludeTags:      []string{"Spicy"},
+		ExcludeAllergens: []string{"peanuts"},
+		MaxPrepTime:      60,
+		Seed:             &seed,
+		DryRun:           true,
+	}
+
+	status, first := generatePlan(app, req)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, seed, first.Seed)
+	assert.True(t, first.DryRun)
+	assert.Len(t, first.Entries, 6)
+
+	seen := make(map[uint]bool)
+	for _, entry := range first.Entries {
+		assert.Contains(t, []uint{1, 2, 3, 4, 5, 6, 7, 8}, entry.DishID)
+		assert.False(t, seen[entry.DishID], "dish %d repeated", entry.DishID)
+		seen[entry.DishID] = true
+	}
+	assert.Equal(t, "2026-03-02", first.Entries[0].Date)
+	assert.Equal(t, "breakfast", first.Entries[0].Slot)
+
+	assert.Len(t, first.Days, 2)
+	for _, day := range first.Days {
+		assert.Equal(t, 3, day.Meals)
+		assert.NotNil(t, day.Deviation)
+		assert.Equal(t, day.Nutrition.Calories-1800, day.Deviation.Calories)
+		assert.Equal(t, day.Nutrition.Proteins-90, day.Deviation.Proteins)
+		assert.Zero(t, day.Deviation.Fats)
+		assert.Less(t, day.Deviation.Calories, 700.0)
+		assert.Greater(t, day.Deviation.Calories, -700.0)
+	}
+
+	_, second := generatePlan(app, req)
+	assert.Equal(t, first.Entries, second.Entries)
+
+	var count int64
+	database.DB.Model(&models.MealPlanEntry{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestGenerateMealPlan_KeepsOrReplacesPlannedSlots(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanGenerateApp()
+	seedGeneratorDishes()
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "lunch", DishID: 11, Servings: 2})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "snack", DishID: 12, Servings: 1})
+
+	seed := int64(1)
+	req := models.MealPlanGenerateRequest{
+		From:    "2026-03-02",
+		To:      "2026-03-02",
+		Slots:   []string{"Dinner", "lunch"},
+		Targets: models.Nutrition{Calories: 2000},
+		Seed:    &seed,
+	}
+
+	status, generated := generatePlan(app, req)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Len(t, generated.Entries, 1)
+	assert.Equal(t, "dinner", generated.Entries[0].Slot)
+	assert.NotZero(t, generated.Entries[0].ID)
+	assert.Equal(t, 3, generated.Days[0].Meals)
+
+	req.Replace = true
+	status, generated = generatePlan(app, req)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Len(t, generated.Entries, 2)
+	assert.Equal(t, "lunch", generated.Entries[0].Slot)
+	assert.Equal(t, "dinner", generated.Entries[1].Slot)
+
+	plan := getMealPlan(app, "from=2026-03-02&to=2026-03-02")
+	assert.Len(t, plan, 3)
+	slots := make(map[string]int)
+	for _, entry := range plan {
+		slots[entry.Slot]++
+	}
+	assert.Equal(t, map[string]int{"lunch": 1, "dinner": 1, "snack": 1}, slots)
+}
+
+func TestGenerateMealPlan_InvalidRequests(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanGenerateApp()
+	seedGeneratorDishes()
+
+	for _, req := range []models.MealPlanGenerateRequest{
+		{From: "2026-03-02", To: "2026-03-08"},
+		{From: "2026-03-08", To: "2026-03-02", Targets: models.Nutrition{Calories: 2000}},
+		{From: "2026-03-02", To: "2026-03-08", Targets: models.Nutrition{Calories: 2000, Fats: -1}},
+		{From: "2026-03-02", To: "2026-03-08", Targets: models.Nutrition{Calories: 2000}, Slots: []string{"brunch"}},
+		{From: "2026-03-02", To: "2026-03-08", Targets: models.Nutrition{Calories: 2000}, ExcludeAllergens: []string{"chocolate"}},
+		{From: "2026-03-02", To: "2026-03-08", Targets: models.Nutrition{Calories: 2000}, MaxPrepTime: 10},
+	} {
+		status, _ := generatePlan(app, req)
+		assert.Equal(t, fiber.StatusBadRequest, status, "%+v", req)
+	}
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMealPlanApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/plans", handlers.GetMealPlan)
+	app.Post("/plans", handlers.AddMealPlanEntry)
+	app.Delete("/plans", handlers.ClearMealPlan)
+	app.Get("/plans/summary", handlers.GetMealPlanSummary)
+	app.Post("/plans/copy-week", handlers.CopyMealPlanWeek)
+	app.Put("/plans/:id", handlers.UpdateMealPlanEntry)
+	app.Delete("/plans/:id", handlers.DeleteMealPlanEntry)
+	return app
+}
+
+func seedMealPlanDishes() {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Porridge", Calories: 300, Fats: 8, Carbs: 50, Proteins: 10, Servings: 1})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Lasagne", Calories: 650, Fats: 30, Carbs: 60, Proteins: 35, Servings: 4})
+}
+
+func getMealPlan(app *fiber.App, query string) []models.MealPlanEntryResponse {
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/plans?"+query, nil))
+	var entries []models.MealPlanEntryResponse
+	json.NewDecoder(resp.Body).Decode(&entries)
+	return entries
+}
+
+func TestMealPlan_CRUD(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanApp()
+	seedMealPlanDishes()
+
+	resp := sendJSON(app, http.MethodPost, "/plans", models.MealPlanRequest{Date: "2026-03-02", Slot: "Dinner", DishID: 2, Servings: 2})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var entry models.MealPlanEntryResponse
+	json.NewDecoder(resp.Body).Decode(&entry)
+	assert.Equal(t, "2026-03-02", entry.Date)
+	assert.Equal(t, models.MealSlotDinner, entry.Slot)
+	assert.Equal(t, "Lasagne", entry.DishName)
+	assert.Equal(t, 1300.0, entry.Nutrition.Calories)
+
+	sendJSON(app, http.MethodPost, "/plans", models.MealPlanRequest{Date: "2026-03-02", Slot: "breakfast", DishID: 1})
+	sendJSON(app, http.MethodPost, "/plans", models.MealPlanRequest{Date: "2026-03-01", Slot: "snack", DishID: 1})
+
+	for _, req := range []models.MealPlanRequest{
+		{Date: "02/03/2026", Slot: "lunch", DishID: 1},
+		{Date: "2026-03-02", Slot: "brunch", DishID: 1},
+		{Date: "2026-03-02", Slot: "lunch", DishID: 1, Servings: -1},
+	} {
+		resp = sendJSON(app, http.MethodPost, "/plans", req)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp = sendJSON(app, http.MethodPost, "/plans", models.MealPlanRequest{Date: "2026-03-02", Slot: "lunch", DishID: 9})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	entries := getMealPlan(app, "week=2026-03-04")
+	assert.Len(t, entries, 2)
+	assert.Equal(t, models.MealSlotBreakfast, entries[0].Slot)
+	assert.Equal(t, models.MealSlotDinner, entries[1].Slot)
+
+	entries = getMealPlan(app, "from=2026-03-01&to=2026-03-02")
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "2026-03-01", entries[0].Date)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/plans?from=2026-03-02&to=2026-03-01", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/plans?from=2026-01-01&to=2026-12-31", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	id := strconv.Itoa(int(entry.ID))
+	resp = sendJSON(app, http.MethodPut, "/plans/"+id, models.MealPlanRequest{Date: "2026-03-03", Slot: "lunch", DishID: 2})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.MealPlanEntryResponse
+	json.NewDecoder(resp.Body).Decode(&updated)
+	assert.Equal(t, "2026-03-03", updated.Date)
+	assert.Equal(t, 1, updated.Servings)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/plans/"+id, nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/plans/"+id, nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/plans", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/plans?week=2026-03-02", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Empty(t, getMealPlan(app, "week=2026-03-02"))
+	assert.Len(t, getMealPlan(app, "from=2026-03-01&to=2026-03-01"), 1)
+}
+
+func TestMealPlan_CopyWeek(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanApp()
+	seedMealPlanDishes()
+
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "breakfast", DishID: 1, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-08"), Slot: "dinner", DishID: 2, Servings: 4})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-10"), Slot: "lunch", DishID: 1, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 2, Date: mustPlanDate("2026-03-03"), Slot: "lunch", DishID: 1, Servings: 1})
+
+	resp := sendJSON(app, http.MethodPost, "/plans/copy-week", models.MealPlanCopyRequest{FromWeek: "2026-03-04"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var copied []models.MealPlanEntryResponse
+	json.NewDecoder(resp.Body).Decode(&copied)
+	assert.Len(t, copied, 3)
+	assert.Equal(t, "2026-03-09", copied[0].Date)
+	assert.Equal(t, "2026-03-10", copied[1].Date)
+	assert.Equal(t, "2026-03-15", copied[2].Date)
+	assert.Equal(t, 4, copied[2].Servings)
+
+	resp = sendJSON(app, http.MethodPost, "/plans/copy-week", models.MealPlanCopyRequest{FromWeek: "2026-03-02", ToWeek: "2026-03-11", Replace: true})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Len(t, getMealPlan(app, "week=2026-03-09"), 2)
+
+	resp = sendJSON(app, http.MethodPost, "/plans/copy-week", models.MealPlanCopyRequest{FromWeek: "2026-03-02", ToWeek: "2026-03-08"})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestMealPlan_Summary(t *testing.T) {
+	setupTestDB()
+	app := setupMealPlanApp()
+	seedMealPlanDishes()
+
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "breakfast", DishID: 1, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-02"), Slot: "dinner", DishID: 2, Servings: 1})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-05"), Slot: "lunch", DishID: 2, Servings: 2})
+	database.DB.Create(&models.MealPlanEntry{UserID: 1, Date: mustPlanDate("2026-03-09"), Slot: "lunch", DishID: 2, Servings: 2})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/plans/summary?week=2026-03-08", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var summary models.MealPlanSummary
+	json.NewDecoder(resp.Body).Decode(&summary)
+	assert.Equal(t, "2026-03-02", summary.From)
+	assert.Equal(t, "2026-03-08", summary.To)
+	assert.Len(t, summary.Days, 2)
+	assert.Equal(t, 2, summary.Days[0].Meals)
+	assert.Equal(t, 950.0, summary.Days[0].Nutrition.Calories)
+	assert.Equal(t, 1300.0, summary.Days[1].Nutrition.Calories)
+	assert.Equal(t, 2250.0, summary.Total.Calories)
+	assert.Equal(t, 115.0, summary.Total.Proteins)
+	assert.Equal(t, 321.4, summary.DailyAverage.Calories)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/plans/summary?from=2026-03-02&to=2026-03-02", nil))
+	summary = models.MealPlanSummary{}
+	json.NewDecoder(resp.Body).Decode(&summary)
+	assert.Equal(t, 950.0, summary.DailyAverage.Calories)
+}
+
+func mustPlanDate(value string) time.Time {
+	date, err := time.Parse(models.MealPlanDateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"foodapp/service"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNotificationApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/notifications", handlers.GetNotifications)
+	app.Put("/notifications/read-all", handlers.MarkAllNotificationsRead)
+	app.Put("/notifications/:id/read", handlers.MarkNotificationRead)
+	app.Get("/dishes/use-up", handlers.GetUseUpDishes)
+	return app
+}
+
+func pantryDate(days int) *time.Time {
+	date := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, days)
+	return &date
+}
+
+func TestNotifyExpiringPantryItems(t *testing.T) {
+	setupTestDB()
+	app := setupNotificationApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Cheese"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Rice"})
+	database.DB.Create(&models.PantryItem{ID: 1, UserID: 1, IngredientID: 1, Quantity: 1, Unit: "l", ExpiresOn: pantryDate(1)})
+	database.DB.Create(&models.PantryItem{ID: 2, UserID: 1, IngredientID: 2, Quantity: 200, Unit: "g", ExpiresOn: pantryDate(10)})
+	database.DB.Create(&models.PantryItem{ID: 3, UserID: 1, IngredientID: 3, Quantity: 1, Unit: "kg"})
+	database.DB.Create(&models.PantryItem{ID: 4, UserID: 2, IngredientID: 2, Quantity: 100, Unit: "g", ExpiresOn: pantryDate(0)})
+	database.DB.Create(&models.PantryItem{ID: 5, UserID: 1, IngredientID: 1, Quantity: 1, Unit: "l", ExpiresOn: pantryDate(-1)})
+
+	created, err := service.NotifyExpiringPantryItems(database.DB, time.Now(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+
+	created, err = service.NotifyExpiringPantryItems(database.DB, time.Now(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/notifications", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var list models.NotificationListResponse
+	json.NewDecoder(resp.Body).Decode(&list)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, int64(1), list.Unread)
+	assert.Equal(t, models.NotificationKindPantryExpiry, list.Items[0].Kind)
+	assert.Equal(t, "Milk expires soon", list.Items[0].Title)
+	assert.Equal(t, "Milk in your pantry expires tomorrow.", list.Items[0].Body)
+
+	id := strconv.Itoa(int(list.Items[0].ID))
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPut, "/notifications/"+id+"/read", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/notifications?unread=true", nil))
+	list = models.NotificationListResponse{}
+	json.NewDecoder(resp.Body).Decode(&list)
+	assert.Equal(t, int64(0), list.Total)
+	assert.Empty(t, list.Items)
+
+	// A new expiry date is a new alert.
+	database.DB.Model(&models.PantryItem{}).Where("id = ?", 1).Update("expires_on", pantryDate(2))
+	created, _ = service.NotifyExpiringPantryItems(database.DB, time.Now(), 3)
+	assert.Equal(t, 1, created)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPut, "/notifications/read-all", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/notifications?unread=true", nil))
+	list = models.NotificationListResponse{}
+	json.NewDecoder(resp.Body).Decode(&list)
+	assert.Equal(t, int64(0), list.Unread)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPut, "/notifications/999/read", nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/notifications?page=0", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetUseUpDishes_RanksByExpiringIngredients(t *testing.T) {
+	setupTestDB()
+	app := setupNotificationApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Spinach"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Cream"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Pasta"})
+	database.DB.Create(&models.Ingredient{ID: 4, Name: "Yoghurt"})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Spinach pasta"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 200, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 100, Unit: "ml"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 3, Quantity: 250, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Creamed spinach"})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 1, Quantity: 300, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 3, Name: "Yoghurt bowl"})
+	database.DB.Create(&models.DishIngredient{DishID: 3, IngredientID: 4, Quantity: 150, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 4, Name: "Plain pasta"})
+	database.DB.Create(&models.DishIngredient{DishID: 4, IngredientID: 3, Quantity: 250, Unit: "g"})
+
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 1, Quantity: 300, Unit: "g", ExpiresOn: pantryDate(2)})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 2, Quantity: 200, Unit: "ml", ExpiresOn: pantryDate(3)})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 4, Quantity: 500, Unit: "g", ExpiresOn: pantryDate(0)})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 3, Quantity: 1, Unit: "kg"})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/dishes/use-up", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var suggestions []models.UseUpSuggestion
+	json.NewDecoder(resp.Body).Decode(&suggestions)
+	assert.Len(t, suggestions, 3)
+	assert.Equal(t, "Spinach pasta", suggestions[0].Dish.Name)
+	assert.Len(t, suggestions[0].Expiring, 2)
+	assert.Equal(t, "Spinach", suggestions[0].Expiring[0].IngredientName)
+	assert.Equal(t, "Yoghurt bowl", suggestions[1].Dish.Name)
+	assert.Equal(t, "Creamed spinach", suggestions[2].Dish.Name)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes/use-up?days=0&limit=1", nil))
+	suggestions = nil
+	json.NewDecoder(resp.Body).Decode(&suggestions)
+	assert.Len(t, suggestions, 1)
+	assert.Equal(t, "Yoghurt bowl", suggestions[0].Dish.Name)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes/use-up?limit=0", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetUseUpDishes_DeletedIngredient(t *testing.T) {
+	setupTestDB()
+	app := setupNotificationApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Spinach"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Sorrel"})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Green soup"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 100, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Creamed spinach"})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 1, Quantity: 300, Unit: "g"})
+
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 1, Quantity: 300, Unit: "g", ExpiresOn: pantryDate(2)})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 2, Quantity: 50, Unit: "g", ExpiresOn: pantryDate(1)})
+	database.DB.Delete(&models.Ingredient{}, 2)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/dishes/use-up", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var suggestions []models.UseUpSuggestion
+	json.NewDecoder(resp.Body).Decode(&suggestions)
+	assert.Len(t, suggestions, 2)
+	assert.Equal(t, "Green soup", suggestions[0].Dish.Name)
+	assert.Len(t, suggestions[0].Expiring, 1)
+	assert.Equal(t, "Creamed spinach", suggestions[1].Dish.Name)
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPantryApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/pantry", handlers.GetPantry)
+	app.Post("/pantry", handlers.AddPantryItem)
+	app.Put("/pantry/:id", handlers.UpdatePantryItem)
+	app.Delete("/pantry/:id", handlers.DeletePantryItem)
+	app.Post("/cart/add-dish", handlers.AddDishToCart)
+	app.Get("/cart/get", handlers.GetUserCart)
+	app.Post("/statistics/add", handlers.AddStatistics)
+	return app
+}
+
+func seedPantryDish() {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Eggs"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Milk"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Pancakes", Servings: 2})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 250, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 2, Unit: "pcs"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 3, Quantity: 500, Unit: "ml"})
+}
+
+func TestPantry_CRUD(t *testing.T) {
+	setupTestDB()
+	app := setupPantryApp()
+	seedPantryDish()
+
+	resp := sendJSON(app, http.MethodPost, "/pantry", models.PantryItemRequest{IngredientID: 1, Quantity: 1, Unit: "kg", ExpiresOn: "2030-01-31"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var item models.PantryItemResponse
+	json.NewDecoder(resp.Body).Decode(&item)
+	assert.Equal(t, "Flour", item.IngredientName)
+	assert.Equal(t, "2030-01-31", item.ExpiresOn)
+	assert.False(t, item.Expired)
+
+	sendJSON(app, http.MethodPost, "/pantry", models.PantryItemRequest{IngredientID: 3, Quantity: 1, Unit: "l", ExpiresOn: "2020-01-01"})
+	sendJSON(app, http.MethodPost, "/pantry", models.PantryItemRequest{IngredientID: 2, Quantity: 6})
+
+	for _, req := range []models.PantryItemRequest{
+		{IngredientID: 1, Quantity: 0},
+		{IngredientID: 1, Quantity: 1, ExpiresOn: "31/01/2030"},
+		{Quantity: 1},
+	} {
+		resp = sendJSON(app, http.MethodPost, "/pantry", req)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp = sendJSON(app, http.MethodPost, "/pantry", models.PantryItemRequest{IngredientID: 9, Quantity: 1})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/pantry", nil))
+	var pantry []models.PantryItemResponse
+	json.NewDecoder(resp.Body).Decode(&pantry)
+	assert.Len(t, pantry, 3)
+	assert.Equal(t, "Milk", pantry[0].IngredientName)
+	assert.True(t, pantry[0].Expired)
+	assert.Equal(t, "Eggs", pantry[2].IngredientName)
+
+	id := strconv.Itoa(int(item.ID))
+	resp = sendJSON(app, http.MethodPut, "/pantry/"+id, models.PantryItemRequest{Quantity: 500, Unit: "g"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.PantryItemResponse
+	json.NewDecoder(resp.Body).Decode(&updated)
+	assert.Equal(t, 500.0, updated.Quantity)
+	assert.Equal(t, "", updated.ExpiresOn)
+	assert.Equal(t, uint(1), updated.IngredientID)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/pantry/"+id, nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/pantry/"+id, nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestAddDishToCart_SkipsWhatPantryCovers(t *testing.T) {
+	setupTestDB()
+	app := setupPantryApp()
+	seedPantryDish()
+
+	expired := time.Now().AddDate(0, 0, -2)
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 1, Quantity: 0.1, Unit: "kg"})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 2, Quantity: 6, Unit: "pcs"})
+	database.DB.Create(&models.PantryItem{UserID: 1, IngredientID: 3, Quantity: 1, Unit: "l", ExpiresOn: &expired})
+
+	resp := sendJSON(app, http.MethodPost, "/cart/add-dish", models.CartAddDishRequest{DishID: 1})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Covered []models.PantryUsage `json:"covered_by_pantry"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Covered, 2)
+	assert.Equal(t, 100.0, body.Covered[0].Quantity)
+
+	cart := getCartLines(app)
+	assert.Len(t, cart, 2)
+	assert.Equal(t, "Flour", cart[0].Ingredient.Name)
+	assert.Equal(t, 150, cart[0].Quantity)
+	assert.Equal(t, "Milk", cart[1].Ingredient.Name)
+	assert.Equal(t, 500, cart[1].Quantity)
+
+	resp = sendJSON(app, http.MethodPost, "/cart/add-dish", models.CartAddDishRequest{DishID: 1, IgnorePantry: true})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Len(t, getCartLines(app), 3)
+
+	var count int64
+	database.DB.Model(&models.PantryItem{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestAddStatistics_DeductsPantry(t *testing.T) {
+	setupTestDB()
+	app := setupPantryApp()
+	seedPantryDish()
+
+	soon := time.Now().AddDate(0, 0, 1).UTC().Truncate(24 * time.Hour)
+	later := soon.AddDate(0, 0, 7)
+	database.DB.Create(&models.PantryItem{ID: 1, UserID: 1, IngredientID: 1, Quantity: 1, Unit: "kg", ExpiresOn: &later})
+	database.DB.Create(&models.PantryItem{ID: 2, UserID: 1, IngredientID: 1, Quantity: 200, Unit: "g", ExpiresOn: &soon})
+	database.DB.Create(&models.PantryItem{ID: 3, UserID: 1, IngredientID: 2, Quantity: 1, Unit: "pcs"})
+	database.DB.Create(&models.PantryItem{ID: 4, UserID: 2, IngredientID: 3, Quantity: 1, Unit: "l"})
+
+	resp := sendJSON(app, http.MethodPost, "/statistics/add", models.StatisticsRequest{DishID: 1})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		Used []models.PantryUsage `json:"pantry_used"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, []models.PantryUsage{
+		{IngredientID: 1, Quantity: 250, Unit: "g"},
+		{IngredientID: 2, Quantity: 1, Unit: "pcs"},
+	}, body.Used)
+
+	var items []models.PantryItem
+	database.DB.Order("id").Find(&items)
+	assert.Len(t, items, 2)
+	assert.Equal(t, uint(1), items[0].ID)
+	assert.Equal(t, 0.95, items[0].Quantity)
+	assert.Equal(t, 1.0, items[1].Quantity)
+}
+
+func getCartLines(app *fiber.App) []models.CartResponse {
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/cart/get", nil))
+	var cart []models.CartResponse
+	json.NewDecoder(resp.Body).Decode(&cart)
+	return cart
+}
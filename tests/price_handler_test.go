@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPriceApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/dishes", handlers.GetAllDishes)
+	app.Get("/ingredients/:id/prices", handlers.GetIngredientPrices)
+	app.Post("/ingredients/:id/prices", handlers.SetIngredientPrice)
+	app.Delete("/ingredients/:id/prices/:price_id", handlers.DeleteIngredientPrice)
+	app.Get("/cart/get", handlers.GetUserCart)
+	return app
+}
+
+func TestIngredientPrices_SetReplacesSameStore(t *testing.T) {
+	setupTestDB()
+	app := setupPriceApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour"})
+
+	resp := sendJSON(app, http.MethodPost, "/ingredients/1/prices", models.IngredientPriceRequest{Price: 1.2, Unit: "kg"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var price models.IngredientPrice
+	json.NewDecoder(resp.Body).Decode(&price)
+	assert.Equal(t, models.DefaultCurrency, price.Currency)
+
+	resp = sendJSON(app, http.MethodPost, "/ingredients/1/prices", models.IngredientPriceRequest{Price: 1.5, Unit: "kg", Currency: "eur"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/ingredients/1/prices", models.IngredientPriceRequest{Price: 0.9, Unit: "kg", Store: "Market"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/ingredients/1/prices", models.IngredientPriceRequest{Price: 1, Unit: "kg", Currency: "euro"})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/ingredients/1/prices", models.IngredientPriceRequest{Price: -1, Unit: "kg"})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/ingredients/9/prices", models.IngredientPriceRequest{Price: 1, Unit: "kg"})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/ingredients/1/prices", nil))
+	var prices []models.IngredientPrice
+	json.NewDecoder(resp.Body).Decode(&prices)
+	assert.Len(t, prices, 2)
+	assert.Equal(t, 1.5, prices[0].Price)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/ingredients/1/prices/"+strconv.Itoa(int(prices[1].ID)), nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/ingredients/1/prices/"+strconv.Itoa(int(prices[1].ID)), nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestGetUserCart_WithTotal(t *testing.T) {
+	setupTestDB()
+	app := setupPriceApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Flour", Section: "pantry"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Milk", Section: "dairy"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Saffron", Section: "spices"})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 1, Quantity: 500, Unit: "g", AddedBy: 1})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 2, Quantity: 2, AddedBy: 1})
+	database.DB.Create(&models.Cart{UserID: 1, IngredientID: 3, Quantity: 1, Unit: "pinch", AddedBy: 1})
+
+	database.DB.Create(&models.IngredientPrice{IngredientID: 1, Price: 2, Unit: "kg", Currency: "EUR"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 1, Price: 1, Unit: "kg", Currency: "EUR", Store: "Market"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 2, Price: 0.99, Unit: "", Currency: "EUR"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 3, Price: 5, Unit: "g", Currency: "EUR"})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/cart/get?total=true", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var cart models.CartWithTotal
+	json.NewDecoder(resp.Body).Decode(&cart)
+	assert.Len(t, cart.Lines, 3)
+	assert.Equal(t, "EUR", cart.Estimate.Currency)
+	assert.Equal(t, 2.98, cart.Estimate.Total)
+	assert.Equal(t, []string{"Saffron"}, cart.Estimate.Unpriced)
+	assert.Equal(t, 1.0, *cart.Lines[0].EstimatedCost)
+	assert.Nil(t, cart.Lines[2].EstimatedCost)
+
+	database.DB.Create(&models.StoreProfile{ID: 1, UserID: 1, Name: "market", IsDefault: true})
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/cart/get?total=true&group_by=section", nil))
+	cart = models.CartWithTotal{}
+	json.NewDecoder(resp.Body).Decode(&cart)
+	assert.Equal(t, 2.48, cart.Estimate.Total)
+	assert.Len(t, cart.Sections, 3)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/cart/get?total=true&currency=USD", nil))
+	cart = models.CartWithTotal{}
+	json.NewDecoder(resp.Body).Decode(&cart)
+	assert.Equal(t, 0.0, cart.Estimate.Total)
+	assert.Len(t, cart.Estimate.Unpriced, 3)
+}
+
+func TestGetAllDishes_CostPerServing(t *testing.T) {
+	setupTestDB()
+	app := setupPriceApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Pasta"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Beef"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 1, Price: 2, Unit: "kg", Currency: "EUR"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 2, Price: 12, Unit: "kg", Currency: "EUR"})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Bolognese", Servings: 4})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 500, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 400, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Aglio e olio", Servings: 2})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 1, Quantity: 250, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 3, Name: "Water", Servings: 1})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/dishes?sort=cost", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var dishes []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&dishes)
+	assert.Len(t, dishes, 3)
+	assert.Equal(t, "Aglio e olio", dishes[0].Dish.Name)
+	assert.Equal(t, 0.25, dishes[0].Cost.PerServing)
+	assert.Equal(t, "Bolognese", dishes[1].Dish.Name)
+	assert.Equal(t, 1.45, dishes[1].Cost.PerServing)
+	assert.Equal(t, 5.8, dishes[1].Cost.Total)
+	assert.Nil(t, dishes[2].Cost)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes?max_cost=1", nil))
+	dishes = nil
+	json.NewDecoder(resp.Body).Decode(&dishes)
+	assert.Len(t, dishes, 1)
+	assert.Equal(t, "Aglio e olio", dishes[0].Dish.Name)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes?max_cost=cheap", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetAllDishes_PartlyPricedDishes(t *testing.T) {
+	setupTestDB()
+	app := setupPriceApp()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Pasta"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Beef"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Truffle"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 1, Price: 2, Unit: "kg", Currency: "EUR"})
+	database.DB.Create(&models.IngredientPrice{IngredientID: 2, Price: 12, Unit: "kg", Currency: "EUR"})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Bolognese", Servings: 4})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 500, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 400, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Tartufo", Servings: 2})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 1, Quantity: 250, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 2, IngredientID: 3, Quantity: 20, Unit: "g"})
+	database.DB.Create(&models.Dish{ID: 3, Name: "Water", Servings: 1})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/dishes?sort=cost", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var dishes []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&dishes)
+	assert.Len(t, dishes, 3)
+	assert.Equal(t, "Bolognese", dishes[0].Dish.Name)
+	assert.True(t, dishes[0].Cost.Complete)
+	assert.Equal(t, "Tartufo", dishes[1].Dish.Name)
+	assert.False(t, dishes[1].Cost.Complete)
+	assert.Equal(t, 0.25, dishes[1].Cost.PerServing)
+	assert.Equal(t, []string{"Truffle"}, dishes[1].Cost.Unpriced)
+	assert.Nil(t, dishes[2].Cost)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes?max_cost=2", nil))
+	dishes = nil
+	json.NewDecoder(resp.Body).Decode(&dishes)
+	assert.Len(t, dishes, 1)
+	assert.Equal(t, "Bolognese", dishes[0].Dish.Name)
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPurchaseApp() *fiber.App {
+	app := fiber.New()
+	as := app.Group("/as/:user_id", func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return c.Next()
+	})
+	as.Post("/cart/add-ingredients", handlers.AddIngredientsToCart)
+	as.Get("/cart/get", handlers.GetUserCart)
+	as.Put("/cart/check", handlers.CheckCartLines)
+	as.Post("/cart/finish-trip", handlers.FinishTrip)
+	as.Get("/cart/history", handlers.GetPurchaseHistory)
+	return app
+}
+
+func seedPurchaseCart(app *fiber.App) []models.CartResponse {
+	database.DB.Create(&models.User{ID: 1, Email: "ana@example.com"})
+	database.DB.Create(&models.User{ID: 2, Email: "luka@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Bread"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Eggs"})
+
+	for _, id := range []uint{1, 2, 3} {
+		sendJSON(app, http.MethodPost, "/as/1/cart/add-ingredients", models.CartRequest{IngredientID: id, Quantity: int(id)})
+	}
+	return getCart(app, "1")
+}
+
+func TestCheckCartLines(t *testing.T) {
+	setupTestDB()
+	app := setupPurchaseApp()
+	cart := seedPurchaseCart(app)
+
+	resp := sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{LineIDs: []uint{cart[0].ID, cart[2].ID}, Checked: true})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	cart = getCart(app, "1")
+	assert.True(t, cart[0].Checked)
+	assert.NotNil(t, cart[0].CheckedAt)
+	assert.False(t, cart[1].Checked)
+	assert.Nil(t, cart[1].CheckedAt)
+	assert.True(t, cart[2].Checked)
+
+	resp = sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{All: true, Checked: false})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, float64(2), body["updated"])
+
+	for _, line := range getCart(app, "1") {
+		assert.False(t, line.Checked)
+		assert.Nil(t, line.CheckedAt)
+	}
+
+	resp = sendJSON(app, http.MethodPut, "/as/2/cart/check", models.CartCheckRequest{LineIDs: []uint{cart[0].ID}, Checked: true})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{Checked: true})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestFinishTrip_ArchivesCheckedLines(t *testing.T) {
+	setupTestDB()
+	app := setupPurchaseApp()
+	cart := seedPurchaseCart(app)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/as/1/cart/finish-trip", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{LineIDs: []uint{cart[0].ID, cart[1].ID}, Checked: true})
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/as/1/cart/finish-trip", nil))
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var purchase models.Purchase
+	json.NewDecoder(resp.Body).Decode(&purchase)
+	assert.Len(t, purchase.Items, 2)
+	assert.Equal(t, "Milk", purchase.Items[0].IngredientName)
+	assert.Equal(t, 2, purchase.Items[1].Quantity)
+	assert.NotNil(t, purchase.Items[0].CheckedAt)
+
+	remaining := getCart(app, "1")
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, "Eggs", remaining[0].Ingredient.Name)
+
+	sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{All: true, Checked: true})
+	app.Test(httptest.NewRequest(http.MethodPost, "/as/1/cart/finish-trip", nil))
+	assert.Len(t, getCart(app, "1"), 0)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/1/cart/history?page_size=1", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var history models.PurchaseListResponse
+	json.NewDecoder(resp.Body).Decode(&history)
+	assert.Equal(t, int64(2), history.Total)
+	assert.Len(t, history.Items, 1)
+	assert.Equal(t, "Eggs", history.Items[0].Items[0].IngredientName)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/2/cart/history", nil))
+	json.NewDecoder(resp.Body).Decode(&history)
+	assert.Equal(t, int64(0), history.Total)
+	assert.Len(t, history.Items, 0)
+}
+
+func TestPurchaseHistory_SharedWithHousehold(t *testing.T) {
+	setupTestDB()
+	app := setupPurchaseApp()
+	seedPurchaseCart(app)
+
+	household := models.Household{Name: "Home", OwnerID: 1}
+	database.DB.Create(&household)
+	database.DB.Create(&models.HouseholdMember{HouseholdID: household.ID, UserID: 1, Role: models.HouseholdRoleOwner})
+	database.DB.Create(&models.HouseholdMember{HouseholdID: household.ID, UserID: 2, Role: models.HouseholdRoleMember})
+	database.DB.Model(&models.User{}).Where("id IN ?", []uint{1, 2}).Update("active_household_id", household.ID)
+
+	sendJSON(app, http.MethodPost, "/as/2/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 1})
+	sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{All: true, Checked: true})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/as/1/cart/finish-trip", nil))
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/2/cart/history", nil))
+	var history models.PurchaseListResponse
+	json.NewDecoder(resp.Body).Decode(&history)
+	assert.Equal(t, int64(1), history.Total)
+	assert.Equal(t, household.ID, *history.Items[0].HouseholdID)
+	assert.Equal(t, uint(2), history.Items[0].Items[0].AddedBy)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/1/cart/history?page=0", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCheckedLine_UncheckedWhenQuantityGrows(t *testing.T) {
+	setupTestDB()
+	app := setupPurchaseApp()
+	app.Post("/as/:user_id/cart/add-dish", func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return handlers.AddDishToCart(c)
+	})
+	cart := seedPurchaseCart(app)
+	database.DB.Create(&models.Dish{ID: 1, Name: "French toast", Servings: 1})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 2, Quantity: 2})
+
+	sendJSON(app, http.MethodPut, "/as/1/cart/check", models.CartCheckRequest{All: true, Checked: true})
+	sendJSON(app, http.MethodPost, "/as/1/cart/add-ingredients", models.CartRequest{IngredientID: 1, Quantity: 1})
+	resp := sendJSON(app, http.MethodPost, "/as/1/cart/add-dish", models.CartAddDishRequest{DishID: 1})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	cart = getCart(app, "1")
+	assert.False(t, cart[0].Checked)
+	assert.Nil(t, cart[0].CheckedAt)
+	assert.Equal(t, 2, cart[0].Quantity)
+	assert.False(t, cart[1].Checked)
+	assert.Equal(t, 4, cart[1].Quantity)
+	assert.True(t, cart[2].Checked)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/as/1/cart/finish-trip", nil))
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var purchase models.Purchase
+	json.NewDecoder(resp.Body).Decode(&purchase)
+	assert.Len(t, purchase.Items, 1)
+	assert.Equal(t, "Eggs", purchase.Items[0].IngredientName)
+}
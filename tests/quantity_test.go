@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"foodapp/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleQuantity_CountUnitsStayWhole(t *testing.T) {
+	assert.Equal(t, 2.0, utils.ScaleQuantity(3, "pcs", 0.5))
+	assert.Equal(t, 1.0, utils.ScaleQuantity(1, "egg", 0.25))
+	assert.Equal(t, 5.0, utils.ScaleQuantity(2, "cloves", 2.5))
+}
+
+func TestScaleQuantity_MeasuredUnits(t *testing.T) {
+	assert.Equal(t, 333.33, utils.ScaleQuantity(500, "g", 2.0/3.0))
+	assert.Equal(t, 0.25, utils.ScaleQuantity(1, "tsp", 0.25))
+	assert.Equal(t, 7.5, utils.ScaleQuantity(5, "", 1.5))
+}
+
+func TestConvertQuantity(t *testing.T) {
+	converted, ok := utils.ConvertQuantity(500, "g", "kg")
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, converted)
+
+	converted, ok = utils.ConvertQuantity(2, "tbsp", "ml")
+	assert.True(t, ok)
+	assert.InDelta(t, 29.57, converted, 0.01)
+
+	converted, ok = utils.ConvertQuantity(3, "eggs", "")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, converted)
+
+	converted, ok = utils.ConvertQuantity(1, "Pinch", "pinch")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, converted)
+
+	_, ok = utils.ConvertQuantity(100, "g", "ml")
+	assert.False(t, ok)
+
+	_, ok = utils.ConvertQuantity(1, "pinch", "g")
+	assert.False(t, ok)
+}
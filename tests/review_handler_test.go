@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/middleware"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupReviewApp() *fiber.App {
+	app := fiber.New()
+	asUser := func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return c.Next()
+	}
+	app.Get("/dishes", handlers.GetAllDishes)
+	app.Get("/dishes/:id/reviews", handlers.GetDishReviews)
+	app.Post("/as/:user_id/dishes/:id/reviews", asUser, handlers.AddDishReview)
+	app.Put("/as/:user_id/reviews/:review_id/hide", asUser, handlers.HideReview)
+	return app
+}
+
+func seedReviewDishes() {
+	database.DB.Create(&models.User{ID: 1, Email: "one@example.com", UserName: "one"})
+	database.DB.Create(&models.User{ID: 2, Email: "two@example.com", UserName: "two"})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Soup"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Stew"})
+}
+
+func postReview(app *fiber.App, userID, dishID string, rating int) *http.Response {
+	requestBody, _ := json.Marshal(models.DishReviewRequest{Rating: rating, Comment: "tasty"})
+	request := httptest.NewRequest(http.MethodPost, "/as/"+userID+"/dishes/"+dishID+"/reviews", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	return resp
+}
+
+func TestAddDishReview_RequiresCooking(t *testing.T) {
+	setupTestDB()
+	app := setupReviewApp()
+	seedReviewDishes()
+
+	resp := postReview(app, "1", "1", 5)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	database.DB.Create(&models.Statistics{UserID: 1, DishId: 1})
+
+	resp = postReview(app, "1", "1", 6)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = postReview(app, "1", "1", 4)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp = postReview(app, "1", "1", 2)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var count int64
+	database.DB.Model(&models.DishReview{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestHideReview_ExcludedFromAverage(t *testing.T) {
+	setupTestDB()
+	app := setupReviewApp()
+	seedReviewDishes()
+
+	database.DB.Create(&models.Statistics{UserID: 1, DishId: 1})
+	database.DB.Create(&models.Statistics{UserID: 2, DishId: 1})
+	postReview(app, "1", "1", 4)
+	postReview(app, "2", "1", 1)
+
+	var spam models.DishReview
+	database.DB.Where("user_id = ?", 2).First(&spam)
+
+	requestBody, _ := json.Marshal(models.ReviewModerationRequest{Reason: "spam"})
+	request := httptest.NewRequest(http.MethodPut, "/as/1/reviews/"+strconv.Itoa(int(spam.ID))+"/hide", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes/1/reviews", nil))
+	var reviews []models.DishReviewResponse
+	json.NewDecoder(resp.Body).Decode(&reviews)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "one", reviews[0].UserName)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes", nil))
+	var dishes []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&dishes)
+	assert.Len(t, dishes, 2)
+	assert.Equal(t, 4.0, dishes[0].Rating.Average)
+	assert.Equal(t, int64(1), dishes[0].Rating.Count)
+}
+
+func TestGetAllDishes_SortByRating(t *testing.T) {
+	setupTestDB()
+	app := setupReviewApp()
+	seedReviewDishes()
+
+	database.DB.Create(&models.DishReview{DishID: 1, UserID: 1, Rating: 3})
+	database.DB.Create(&models.DishReview{DishID: 2, UserID: 1, Rating: 5})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/dishes?sort=rating", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var dishes []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&dishes)
+	assert.Len(t, dishes, 2)
+	assert.Equal(t, "Stew", dishes[0].Dish.Name)
+	assert.Equal(t, "Soup", dishes[1].Dish.Name)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/dishes?sort=colour", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHideReview_EditorPromotedByAdmin(t *testing.T) {
+	setupTestDB()
+	seedReviewDishes()
+	database.DB.Create(&models.User{ID: 3, Email: "admin@example.com"})
+	assert.NoError(t, database.PromoteAdmins([]string{"admin@example.com"}))
+
+	app := fiber.New()
+	asActor := func(c *fiber.Ctx) error {
+		actor, _ := c.ParamsInt("actor")
+		c.Locals("userID", uint(actor))
+		return c.Next()
+	}
+	moderators := middleware.RoleRequired(models.RoleEditor, models.RoleAdmin)
+	app.Put("/as/:actor/users/:user_id/role", asActor, middleware.RoleRequired(models.RoleAdmin), handlers.UpdateUserRole)
+	app.Put("/as/:actor/reviews/:review_id/hide", asActor, moderators, handlers.HideReview)
+
+	database.DB.Create(&models.DishReview{ID: 1, UserID: 2, DishID: 1, Rating: 1})
+	hide := func(actor string) int {
+		return sendJSON(app, http.MethodPut, "/as/"+actor+"/reviews/1/hide", models.ReviewModerationRequest{Reason: "spam"}).StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, hide("1"))
+	resp := sendJSON(app, http.MethodPut, "/as/3/users/1/role", models.RoleRequest{Role: models.RoleEditor})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.StatusOK, hide("1"))
+}
@@ -23,8 +23,36 @@ func setupTestDB() {
 		&models.Ingredient{},
 		&models.DishIngredient{},
 		&models.Cart{},
+		&models.CartDishContribution{},
 		&models.FavoriteDish{},
 		&models.Statistics{},
+		&models.DishStep{},
+		&models.DishStepIngredient{},
+		&models.Tag{},
+		&models.DishTag{},
+		&models.IngredientAllergen{},
+		&models.UserAllergen{},
+		&models.UserDiet{},
+		&models.IngredientSubstitution{},
+		&models.IngredientSynonym{},
+		&models.DishReview{},
+		&models.ShareLink{},
+		&models.ShareAccess{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.HouseholdInvite{},
+		&models.Purchase{},
+		&models.PurchaseItem{},
+		&models.StoreProfile{},
+		&models.StoreSection{},
+		&models.IngredientPrice{},
+		&models.PantryItem{},
+		&models.Notification{},
+		&models.MealPlanEntry{},
+		&models.MealPlanFeed{},
+		&models.NutritionGoal{},
+		&models.FavoriteCollection{},
+		&models.DataMigration{},
 	)
 	
 	database.DB = db
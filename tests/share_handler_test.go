@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupShareApp() *fiber.App {
+	app := fiber.New()
+	shares := app.Group("/shares", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	shares.Get("/", handlers.GetShares)
+	shares.Post("/", handlers.CreateShare)
+	shares.Delete("/:id", handlers.RevokeShare)
+	shares.Get("/:id/accesses", handlers.GetShareAccesses)
+
+	app.Get("/shared/:token", handlers.GetSharedResource)
+	app.Post("/shared/:token/cart", handlers.AddSharedCartIngredient)
+	app.Delete("/shared/:token/cart/:ingredient_id", handlers.RemoveSharedCartIngredient)
+	app.Post("/shared/:token/dishes", handlers.AddSharedCollectionDish)
+	return app
+}
+
+func createShare(t *testing.T, app *fiber.App, req models.ShareRequest) models.ShareLink {
+	resp := sendJSON(app, http.MethodPost, "/shares", req)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var share models.ShareLink
+	json.NewDecoder(resp.Body).Decode(&share)
+	return share
+}
+
+func TestShare_CollectionReadOnly(t *testing.T) {
+	setupTestDB()
+	app := setupShareApp()
+	seedCollectionDishes()
+
+	collection := models.FavoriteCollection{UserID: 1, Name: "Weeknight"}
+	database.DB.Create(&collection)
+	database.DB.Create(&models.FavoriteDish{UserID: 1, DishID: 1, CollectionID: collection.ID, Note: "kids love it", Position: 1})
+
+	share := createShare(t, app, models.ShareRequest{ResourceType: models.ShareResourceCollection, ResourceID: collection.ID})
+	assert.Equal(t, models.SharePermissionRead, share.Permission)
+	assert.NotEmpty(t, share.Token)
+
+	request := httptest.NewRequest(http.MethodGet, "/shared/"+share.Token, nil)
+	request.Header.Set("User-Agent", "partner-phone")
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var resource models.SharedResource
+	json.NewDecoder(resp.Body).Decode(&resource)
+	assert.Equal(t, models.ShareResourceCollection, resource.ResourceType)
+	assert.Len(t, resource.Collection.Dishes, 1)
+	assert.Equal(t, "kids love it", resource.Collection.Dishes[0].Note)
+
+	resp = sendJSON(app, http.MethodPost, "/shared/"+share.Token+"/dishes", models.CollectionDishRequest{DishID: 2})
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/shares/"+strconv.Itoa(int(share.ID))+"/accesses", nil))
+	var accesses []models.ShareAccess
+	json.NewDecoder(resp.Body).Decode(&accesses)
+	assert.Len(t, accesses, 1)
+	assert.Equal(t, "view", accesses[0].Action)
+	assert.Equal(t, "partner-phone", accesses[0].UserAgent)
+}
+
+func TestShare_CartEditAndRevoke(t *testing.T) {
+	setupTestDB()
+	app := setupShareApp()
+	seedCollectionDishes()
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk"})
+
+	share := createShare(t, app, models.ShareRequest{ResourceType: models.ShareResourceCart, Permission: models.SharePermissionEdit})
+
+	resp := sendJSON(app, http.MethodPost, "/shared/"+share.Token+"/cart", models.CartRequest{IngredientID: 1, Quantity: 2})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var line models.Cart
+	database.DB.Where("user_id = ? AND ingredient_id = ?", 1, 1).First(&line)
+	assert.Equal(t, 2, line.Quantity)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/shared/"+share.Token, nil))
+	var resource models.SharedResource
+	json.NewDecoder(resp.Body).Decode(&resource)
+	assert.Len(t, resource.Cart, 1)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/shares/"+strconv.Itoa(int(share.ID)), nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/shared/"+share.Token, nil))
+	assert.Equal(t, fiber.StatusGone, resp.StatusCode)
+}
+
+func TestShare_Expiry(t *testing.T) {
+	setupTestDB()
+	app := setupShareApp()
+	seedCollectionDishes()
+
+	past := time.Now().Add(-time.Hour)
+	resp := sendJSON(app, http.MethodPost, "/shares", models.ShareRequest{ResourceType: models.ShareResourceCart, ExpiresAt: &past})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	database.DB.Create(&models.ShareLink{UserID: 1, ResourceType: models.ShareResourceCart, Token: "expired", Permission: models.SharePermissionRead, ExpiresAt: &past})
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/shared/expired", nil))
+	assert.Equal(t, fiber.StatusGone, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/shared/unknown", nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
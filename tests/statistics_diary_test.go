@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNutritionDiaryApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Get("/users/profile", handlers.GetUserProfile)
+	app.Put("/users/profile/timezone", handlers.UpdateUserTimezone)
+	app.Get("/statistics/diary", handlers.GetNutritionDiary)
+	return app
+}
+
+func getDiary(t *testing.T, app *fiber.App, query string) models.NutritionDiaryResponse {
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/statistics/diary?"+query, nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var diary models.NutritionDiaryResponse
+	json.NewDecoder(resp.Body).Decode(&diary)
+	return diary
+}
+
+func seedDiary(timezone string) {
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com", Timezone: timezone})
+	database.DB.Create(&models.Dish{ID: 1, Name: "Porridge", Calories: 300, Proteins: 10, Fats: 5, Carbs: 50})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Steak", Calories: 700, Proteins: 60, Fats: 45, Carbs: 2})
+}
+
+func eat(userID, dishID uint, at time.Time) {
+	database.DB.Create(&models.Statistics{UserID: userID, DishId: dishID, CreatedAt: at})
+}
+
+func TestUpdateUserTimezone(t *testing.T) {
+	setupTestDB()
+	app := setupNutritionDiaryApp()
+	database.DB.Create(&models.User{ID: 1, Email: "test@example.com"})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/users/profile", nil))
+	var profile models.UserResponse
+	json.NewDecoder(resp.Body).Decode(&profile)
+	assert.Equal(t, "UTC", profile.Timezone)
+
+	for _, timezone := range []string{"", "Local", "Mars/Olympus_Mons"} {
+		resp = sendJSON(app, http.MethodPut, "/users/profile/timezone", models.TimezoneRequest{Timezone: timezone})
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, timezone)
+	}
+
+	resp = sendJSON(app, http.MethodPut, "/users/profile/timezone", models.TimezoneRequest{Timezone: "Asia/Tbilisi"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/users/profile", nil))
+	json.NewDecoder(resp.Body).Decode(&profile)
+	assert.Equal(t, "Asia/Tbilisi", profile.Timezone)
+}
+
+func TestGetNutritionDiary_DaysInUserTimezone(t *testing.T) {
+	setupTestDB()
+	app := setupNutritionDiaryApp()
+	seedDiary("Asia/Tbilisi")
+
+	tbilisi, _ := time.LoadLocation("Asia/Tbilisi")
+	eat(1, 1, time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC))  // 01:30 on the 2nd in Tbilisi
+	eat(1, 2, time.Date(2026, 3, 2, 23, 59, 0, 0, tbilisi))   // stored with +04:00
+	eat(1, 1, time.Date(2026, 3, 2, 20, 0, 0, 0, time.UTC))   // midnight on the 3rd
+	eat(1, 2, time.Date(2026, 3, 1, 19, 59, 59, 0, time.UTC)) // still the 1st
+	eat(2, 2, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC))   // someone else
+
+	diary := getDiary(t, app, "from=2026-03-01&to=2026-03-03")
+	assert.Equal(t, "Asia/Tbilisi", diary.Timezone)
+	assert.Equal(t, "day", diary.Group)
+	assert.Equal(t, []models.NutritionDiaryPeriod{
+		{From: "2026-03-01", To: "2026-03-01", Dishes: 1, Nutrition: models.Nutrition{Calories: 700, Proteins: 60, Fats: 45, Carbs: 2}},
+		{From: "2026-03-02", To: "2026-03-02", Dishes: 2, Nutrition: models.Nutrition{Calories: 1000, Proteins: 70, Fats: 50, Carbs: 52}},
+		{From: "2026-03-03", To: "2026-03-03", Dishes: 1, Nutrition: models.Nutrition{Calories: 300, Proteins: 10, Fats: 5, Carbs: 50}},
+	}, diary.Periods)
+	assert.Equal(t, 4, diary.Total.Dishes)
+	assert.Equal(t, 2000.0, diary.Total.Nutrition.Calories)
+
+	diary = getDiary(t, app, "from=2026-03-04&to=2026-03-05")
+	assert.Len(t, diary.Periods, 2)
+	assert.Zero(t, diary.Total.Dishes)
+}
+
+func TestGetNutritionDiary_WeeksAndMonths(t *testing.T) {
+	setupTestDB()
+	app := setupNutritionDiaryApp()
+	seedDiary("Europe/Berlin")
+
+	// Clocks go forward in Berlin on 29 March 2026; 22:30 UTC that day is
+	// already half past midnight on the 30th, a Monday.
+	eat(1, 1, time.Date(2026, 3, 29, 21, 30, 0, 0, time.UTC))
+	eat(1, 2, time.Date(2026, 3, 29, 22, 30, 0, 0, time.UTC))
+	eat(1, 2, time.Date(2026, 4, 2, 12, 0, 0, 0, time.UTC))
+
+	diary := getDiary(t, app, "group=week&from=2026-03-25&to=2026-04-08")
+	assert.Equal(t, []models.NutritionDiaryPeriod{
+		{From: "2026-03-25", To: "2026-03-29", Dishes: 1, Nutrition: models.Nutrition{Calories: 300, Proteins: 10, Fats: 5, Carbs: 50}},
+		{From: "2026-03-30", To: "2026-04-05", Dishes: 2, Nutrition: models.Nutrition{Calories: 1400, Proteins: 120, Fats: 90, Carbs: 4}},
+		{From: "2026-04-06", To: "2026-04-08", Dishes: 0},
+	}, diary.Periods)
+
+	diary = getDiary(t, app, "group=month&from=2026-03-15&to=2026-04-30")
+	assert.Len(t, diary.Periods, 2)
+	assert.Equal(t, "2026-03-15", diary.Periods[0].From)
+	assert.Equal(t, "2026-03-31", diary.Periods[0].To)
+	assert.Equal(t, 2, diary.Periods[0].Dishes)
+	assert.Equal(t, "2026-04-01", diary.Periods[1].From)
+	assert.Equal(t, 1, diary.Periods[1].Dishes)
+
+	diary = getDiary(t, app, "group=week")
+	assert.Len(t, diary.Periods, 4)
+}
+
+func TestGetNutritionDiary_InvalidQuery(t *testing.T) {
+	setupTestDB()
+	app := setupNutritionDiaryApp()
+	seedDiary("")
+
+	for _, query := range []string{
+		"group=year",
+		"from=2026-03-08&to=2026-03-01",
+		"from=2025-01-01&to=2026-03-01",
+		"from=March",
+	} {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/statistics/diary?"+query, nil))
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+}
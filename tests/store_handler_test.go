@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStoreApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/ingredients/add", handlers.AddIngredient)
+	app.Put("/ingredients/:id", handlers.UpdateIngredient)
+	as := app.Group("/as/:user_id", func(c *fiber.Ctx) error {
+		userID, _ := c.ParamsInt("user_id")
+		c.Locals("userID", uint(userID))
+		return c.Next()
+	})
+	as.Get("/stores", handlers.GetStoreProfiles)
+	as.Post("/stores", handlers.CreateStoreProfile)
+	as.Put("/stores/:id", handlers.UpdateStoreProfile)
+	as.Delete("/stores/:id", handlers.DeleteStoreProfile)
+	as.Post("/cart/add-ingredients", handlers.AddIngredientsToCart)
+	as.Get("/cart/get", handlers.GetUserCart)
+	return app
+}
+
+func seedSectionedCart(app *fiber.App) {
+	database.DB.Create(&models.User{ID: 1, Email: "ana@example.com"})
+	database.DB.Create(&models.User{ID: 2, Email: "luka@example.com"})
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Milk", Section: "dairy"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Apples", Section: "produce"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Bread", Section: "bakery"})
+	database.DB.Create(&models.Ingredient{ID: 4, Name: "Butter", Section: "dairy"})
+	database.DB.Create(&models.Ingredient{ID: 5, Name: "Candles"})
+
+	for _, id := range []uint{1, 2, 3, 4, 5} {
+		sendJSON(app, http.MethodPost, "/as/1/cart/add-ingredients", models.CartRequest{IngredientID: id, Quantity: 1})
+	}
+}
+
+func getCartSections(t *testing.T, app *fiber.App, url string) []models.CartSectionResponse {
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, url, nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var sections []models.CartSectionResponse
+	json.NewDecoder(resp.Body).Decode(&sections)
+	return sections
+}
+
+func sectionNames(sections []models.CartSectionResponse) []string {
+	names := []string{}
+	for _, section := range sections {
+		names = append(names, section.Section)
+	}
+	return names
+}
+
+func TestIngredientSection_Validated(t *testing.T) {
+	setupTestDB()
+	app := setupStoreApp()
+
+	resp := sendJSON(app, http.MethodPost, "/ingredients/add", models.IngredientRequest{Name: "Milk", Section: "Dairy"})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var ingredient models.Ingredient
+	database.DB.Where("name = ?", "Milk").First(&ingredient)
+	assert.Equal(t, "dairy", ingredient.Section)
+
+	resp = sendJSON(app, http.MethodPost, "/ingredients/add", models.IngredientRequest{Name: "Tea", Section: "aisle 9"})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPut, "/ingredients/"+strconv.Itoa(int(ingredient.ID)), models.IngredientRequest{Name: "Milk"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var response models.IngredientResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "dairy", response.Section)
+}
+
+func TestGetUserCart_GroupBySection(t *testing.T) {
+	setupTestDB()
+	app := setupStoreApp()
+	seedSectionedCart(app)
+
+	sections := getCartSections(t, app, "/as/1/cart/get?group_by=section")
+	assert.Equal(t, []string{"produce", "bakery", "dairy", "other"}, sectionNames(sections))
+	assert.Equal(t, "Butter", sections[2].Lines[0].Ingredient.Name)
+	assert.Equal(t, "Milk", sections[2].Lines[1].Ingredient.Name)
+	assert.Equal(t, "Candles", sections[3].Lines[0].Ingredient.Name)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/as/1/cart/get?group_by=aisle", nil))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestStoreProfiles_OrderCart(t *testing.T) {
+	setupTestDB()
+	app := setupStoreApp()
+	seedSectionedCart(app)
+
+	resp := sendJSON(app, http.MethodPost, "/as/1/stores", models.StoreProfileRequest{Name: "Corner shop", Sections: []string{"dairy", "Bakery"}})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var corner models.StoreProfileResponse
+	json.NewDecoder(resp.Body).Decode(&corner)
+	assert.Equal(t, []string{"dairy", "bakery", "produce"}, corner.Sections[:3])
+	assert.Len(t, corner.Sections, len(models.GrocerySections))
+
+	cornerID := strconv.Itoa(int(corner.ID))
+	sections := getCartSections(t, app, "/as/1/cart/get?group_by=section&store="+cornerID)
+	assert.Equal(t, []string{"dairy", "bakery", "produce", "other"}, sectionNames(sections))
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/2/cart/get?group_by=section&store="+cornerID, nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/as/1/stores", models.StoreProfileRequest{Name: "Market", Sections: []string{"other", "produce"}, IsDefault: true})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	sections = getCartSections(t, app, "/as/1/cart/get?group_by=section")
+	assert.Equal(t, []string{"other", "produce", "bakery", "dairy"}, sectionNames(sections))
+
+	resp = sendJSON(app, http.MethodPut, "/as/1/stores/"+cornerID, models.StoreProfileRequest{Name: "Corner shop", Sections: []string{"dairy"}, IsDefault: true})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/as/1/stores", nil))
+	var stores []models.StoreProfileResponse
+	json.NewDecoder(resp.Body).Decode(&stores)
+	assert.Len(t, stores, 2)
+	assert.True(t, stores[0].IsDefault)
+	assert.False(t, stores[1].IsDefault)
+
+	resp = sendJSON(app, http.MethodPost, "/as/1/stores", models.StoreProfileRequest{Name: "Bad", Sections: []string{"aisle 9"}})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/as/1/stores/"+cornerID, nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var count int64
+	database.DB.Model(&models.StoreSection{}).Where("store_profile_id = ?", corner.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSubstitutionApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/ingredients/:id/substitutes", handlers.GetIngredientSubstitutes)
+	app.Post("/ingredients/:id/substitutes", handlers.AddIngredientSubstitute)
+	app.Delete("/ingredients/:id/substitutes/:substitution_id", handlers.DeleteIngredientSubstitute)
+	app.Get("/dishes-ingredients/:dish_id", handlers.GetDishIngredients)
+	app.Post("/cart/swap", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return handlers.SwapCartIngredient(c)
+	})
+	return app
+}
+
+func seedSubstitutions() {
+	database.DB.Create(&models.Ingredient{ID: 1, Name: "Butter"})
+	database.DB.Create(&models.Ingredient{ID: 2, Name: "Olive oil"})
+	database.DB.Create(&models.Ingredient{ID: 3, Name: "Flour"})
+	database.DB.Create(&models.IngredientSubstitution{
+		ID:            1,
+		IngredientID:  1,
+		SubstituteID:  2,
+		Ratio:         0.75,
+		Notes:         "Works for sautéing, not for pastry",
+		DietaryReason: "vegan",
+	})
+}
+
+func TestAddIngredientSubstitute_Self(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+
+	requestBody, _ := json.Marshal(models.SubstitutionRequest{SubstituteID: 1})
+	request := httptest.NewRequest(http.MethodPost, "/ingredients/1/substitutes", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAddIngredientSubstitute_Success(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+
+	requestBody, _ := json.Marshal(models.SubstitutionRequest{SubstituteID: 2, DietaryReason: "Milk"})
+	request := httptest.NewRequest(http.MethodPost, "/ingredients/1/substitutes", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "/ingredients/1/substitutes?diet=milk", nil)
+	resp, _ = app.Test(request)
+
+	var substitutes []models.SubstitutionResponse
+	json.NewDecoder(resp.Body).Decode(&substitutes)
+	assert.Len(t, substitutes, 1)
+	assert.Equal(t, 1.0, substitutes[0].Ratio)
+	assert.Equal(t, "Olive oil", substitutes[0].Substitute.Name)
+}
+
+func TestGetDishIngredients_SubstituteForDiet(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Roux"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 1, Quantity: 100, Unit: "g"})
+	database.DB.Create(&models.DishIngredient{DishID: 1, IngredientID: 3, Quantity: 50, Unit: "g"})
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes-ingredients/1?substitute_for=vegan", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body []models.DishIngredientResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body, 2)
+	assert.Equal(t, "Olive oil", body[0].Ingredient.Name)
+	assert.Equal(t, 75.0, body[0].Quantity)
+	assert.Equal(t, "Butter", body[0].SubstitutedFor.Name)
+	assert.Nil(t, body[1].SubstitutedFor)
+}
+
+func TestSwapCartIngredient_MergesIntoSubstituteLine(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+
+	database.DB.Create(&models.Cart{ID: 1, UserID: 1, IngredientID: 1, Quantity: 200})
+	database.DB.Create(&models.Cart{ID: 2, UserID: 1, IngredientID: 2, Quantity: 10})
+	database.DB.Create(&models.CartDishContribution{CartID: 1, UserID: 1, DishID: 5, Quantity: 100})
+
+	requestBody, _ := json.Marshal(models.CartSwapRequest{IngredientID: 1, SubstituteID: 2})
+	request := httptest.NewRequest(http.MethodPost, "/cart/swap", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var lines []models.Cart
+	database.DB.Where("user_id = ?", 1).Find(&lines)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, 160, lines[0].Quantity)
+
+	var contribution models.CartDishContribution
+	database.DB.Where("dish_id = ?", 5).First(&contribution)
+	assert.Equal(t, uint(2), contribution.CartID)
+	assert.Equal(t, 75, contribution.Quantity)
+}
+
+func TestSwapCartIngredient_UnknownSubstitute(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+
+	requestBody, _ := json.Marshal(models.CartSwapRequest{IngredientID: 1, SubstituteID: 3})
+	request := httptest.NewRequest(http.MethodPost, "/cart/swap", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestSwapCartIngredient_AmbiguousSubstitution(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+	database.DB.Create(&models.IngredientSubstitution{ID: 2, IngredientID: 1, SubstituteID: 2, Ratio: 0.8, DietaryReason: "dairy_free"})
+	database.DB.Create(&models.Cart{ID: 1, UserID: 1, IngredientID: 1, Quantity: 100})
+
+	resp := sendJSON(app, http.MethodPost, "/cart/swap", models.CartSwapRequest{IngredientID: 1, SubstituteID: 2})
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/cart/swap", models.CartSwapRequest{IngredientID: 1})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/cart/swap", models.CartSwapRequest{IngredientID: 3, SubstitutionID: 2})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp = sendJSON(app, http.MethodPost, "/cart/swap", models.CartSwapRequest{IngredientID: 1, SubstituteID: 2, SubstitutionID: 2})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var line models.Cart
+	database.DB.First(&line, 1)
+	assert.Equal(t, uint(2), line.IngredientID)
+	assert.Equal(t, 80, line.Quantity)
+}
+
+func TestSwapCartIngredient_EveryLineByUnit(t *testing.T) {
+	setupTestDB()
+	app := setupSubstitutionApp()
+	seedSubstitutions()
+
+	database.DB.Create(&models.Cart{ID: 1, UserID: 1, IngredientID: 1, Quantity: 500, Unit: "g"})
+	database.DB.Create(&models.Cart{ID: 2, UserID: 1, IngredientID: 1, Quantity: 2, Unit: "pcs"})
+	database.DB.Create(&models.Cart{ID: 3, UserID: 1, IngredientID: 2, Quantity: 2, Unit: "kg"})
+	database.DB.Create(&models.CartDishContribution{CartID: 1, UserID: 1, DishID: 5, Quantity: 200})
+	database.DB.Create(&models.CartDishContribution{CartID: 3, UserID: 1, DishID: 6, Quantity: 1})
+
+	resp := sendJSON(app, http.MethodPost, "/cart/swap", models.CartSwapRequest{IngredientID: 1, SubstituteID: 2})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		CartIDs []uint `json:"cart_ids"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, []uint{3, 2}, body.CartIDs)
+
+	var lines []models.Cart
+	database.DB.Where("user_id = ?", 1).Order("id").Find(&lines)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, models.Cart{ID: 2, UserID: 1, IngredientID: 2, Quantity: 2, Unit: "pcs"}, lines[0])
+		assert.Equal(t, uint(2), lines[1].IngredientID)
+		assert.Equal(t, 2375, lines[1].Quantity)
+		assert.Equal(t, "g", lines[1].Unit)
+	}
+
+	contributions := make(map[uint]int)
+	var rows []models.CartDishContribution
+	database.DB.Where("cart_id = ?", 3).Find(&rows)
+	for _, row := range rows {
+		contributions[row.DishID] = row.Quantity
+	}
+	assert.Equal(t, map[uint]int{5: 150, 6: 1000}, contributions)
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTagApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/dishes", handlers.GetAllDishes)
+	app.Get("/dishes/search", handlers.SearchDishesByName)
+	app.Post("/dishes/:id/tags", handlers.AddDishTags)
+	app.Delete("/dishes/:id/tags/:tag_id", handlers.RemoveDishTag)
+	app.Get("/tags", handlers.GetTags)
+	app.Post("/tags/add", handlers.AddTag)
+	app.Put("/tags/:id", handlers.UpdateTag)
+	app.Delete("/tags/:id", handlers.DeleteTag)
+	return app
+}
+
+func seedTaggedDishes() {
+	database.DB.Create(&models.Tag{ID: 1, Name: "vegan", Type: models.TagTypeDiet})
+	database.DB.Create(&models.Tag{ID: 2, Name: "italian", Type: models.TagTypeCuisine})
+	database.DB.Create(&models.Tag{ID: 3, Name: "keto", Type: models.TagTypeDiet})
+
+	database.DB.Create(&models.Dish{ID: 1, Name: "Vegan Pasta"})
+	database.DB.Create(&models.Dish{ID: 2, Name: "Carbonara"})
+	database.DB.Create(&models.Dish{ID: 3, Name: "Salad"})
+
+	database.DB.Create(&models.DishTag{DishID: 1, TagID: 1})
+	database.DB.Create(&models.DishTag{DishID: 1, TagID: 2})
+	database.DB.Create(&models.DishTag{DishID: 2, TagID: 2})
+	database.DB.Create(&models.DishTag{DishID: 3, TagID: 1})
+}
+
+func TestAddTag_InvalidType(t *testing.T) {
+	setupTestDB()
+	app := setupTagApp()
+
+	requestBody, _ := json.Marshal(models.TagRequest{Name: "Vegan", Type: "colour"})
+	request := httptest.NewRequest(http.MethodPost, "/tags/add", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAddTag_Duplicate(t *testing.T) {
+	setupTestDB()
+	app := setupTagApp()
+
+	database.DB.Create(&models.Tag{Name: "vegan", Type: models.TagTypeDiet})
+
+	requestBody, _ := json.Marshal(models.TagRequest{Name: " Vegan ", Type: models.TagTypeDiet})
+	request := httptest.NewRequest(http.MethodPost, "/tags/add", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestGetAllDishes_FilterByTags(t *testing.T) {
+	setupTestDB()
+	app := setupTagApp()
+	seedTaggedDishes()
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes?tags=vegan,italian", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var anyMatch []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&anyMatch)
+	assert.Len(t, anyMatch, 3)
+
+	request = httptest.NewRequest(http.MethodGet, "/dishes?tags=vegan,italian&match=all", nil)
+	resp, _ = app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var allMatch []models.DishWithIngredients
+	json.NewDecoder(resp.Body).Decode(&allMatch)
+	assert.Len(t, allMatch, 1)
+	assert.Equal(t, "Vegan Pasta", allMatch[0].Dish.Name)
+	assert.Len(t, allMatch[0].Tags, 2)
+}
+
+func TestSearchDishesByName_TagFacets(t *testing.T) {
+	setupTestDB()
+	app := setupTagApp()
+	seedTaggedDishes()
+
+	request := httptest.NewRequest(http.MethodGet, "/dishes/search?q=a&facets=true", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body models.DishSearchResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Dishes, 3)
+
+	counts := map[string]int64{}
+	for _, facet := range body.Facets {
+		counts[facet.Name] = facet.Count
+	}
+	assert.Equal(t, map[string]int64{"vegan": 2, "italian": 2}, counts)
+}
+
+func TestAddDishTags_UnknownTag(t *testing.T) {
+	setupTestDB()
+	app := setupTagApp()
+	seedTaggedDishes()
+
+	requestBody, _ := json.Marshal(models.DishTagsRequest{TagIDs: []uint{3, 99}})
+	request := httptest.NewRequest(http.MethodPost, "/dishes/2/tags", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestDeleteTag_RemovesDishLinks(t *testing.T) {
+	setupTestDB()
+	app := setupTagApp()
+	seedTaggedDishes()
+
+	request := httptest.NewRequest(http.MethodDelete, "/tags/1", nil)
+	resp, _ := app.Test(request)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var links int64
+	database.DB.Model(&models.DishTag{}).Where("tag_id = ?", 1).Count(&links)
+	assert.Equal(t, int64(0), links)
+}
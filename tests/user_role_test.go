@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"foodapp/database"
+	"foodapp/handlers"
+	"foodapp/middleware"
+	"foodapp/models"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupUserRoleApp acts as the user given by the :actor prefix.
+func setupUserRoleApp() *fiber.App {
+	app := fiber.New()
+	app.Put("/as/:actor/users/:user_id/role", func(c *fiber.Ctx) error {
+		actor, _ := c.ParamsInt("actor")
+		c.Locals("userID", uint(actor))
+		return c.Next()
+	}, middleware.RoleRequired(models.RoleAdmin), handlers.UpdateUserRole)
+	return app
+}
+
+func TestPromoteAdmins(t *testing.T) {
+	setupTestDB()
+	database.DB.Create(&models.User{ID: 1, Email: "Chef@Example.com"})
+	database.DB.Create(&models.User{ID: 2, Email: "cook@example.com"})
+
+	assert.NoError(t, database.PromoteAdmins(nil))
+	assert.NoError(t, database.PromoteAdmins([]string{"chef@example.com", "nobody@example.com"}))
+
+	var users []models.User
+	database.DB.Order("id").Find(&users)
+	assert.Equal(t, models.RoleAdmin, users[0].Role)
+	assert.Equal(t, models.RoleUser, users[1].Role)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	setupTestDB()
+	app := setupUserRoleApp()
+	database.DB.Create(&models.User{ID: 1, Email: "admin@example.com", Role: models.RoleAdmin})
+	database.DB.Create(&models.User{ID: 2, Email: "cook@example.com"})
+
+	send := func(actor, url string, role string) int {
+		return sendJSON(app, http.MethodPut, "/as/"+actor+url, models.RoleRequest{Role: role}).StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, send("2", "/users/2/role", "admin"))
+	assert.Equal(t, fiber.StatusBadRequest, send("1", "/users/2/role", "chef"))
+	assert.Equal(t, fiber.StatusNotFound, send("1", "/users/9/role", "editor"))
+	assert.Equal(t, fiber.StatusBadRequest, send("1", "/users/1/role", "user"))
+
+	assert.Equal(t, fiber.StatusOK, send("1", "/users/2/role", "Editor"))
+	var user models.User
+	database.DB.First(&user, 2)
+	assert.Equal(t, models.RoleEditor, user.Role)
+}
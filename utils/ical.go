@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ICalEvent is one event of a calendar made by ICalendar. Start is written
+// as floating local time, so the event happens at that wall-clock time in
+// whatever time zone the calendar app is in.
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	Duration    time.Duration
+	Modified    time.Time
+}
+
+const (
+	icalFloatingLayout = "20060102T150405"
+	icalUTCLayout      = "20060102T150405Z"
+
+	// icalLineOctets is the longest a content line may be before it has to
+	// be folded (RFC 5545, section 3.1).
+	icalLineOctets = 75
+)
+
+// ICalendar writes an RFC 5545 calendar named name holding events. Calendar
+// apps match events by UID, so events with a stable UID replace their older
+// copies when the calendar is fetched again.
+func ICalendar(name string, events []ICalEvent) []byte {
+	var b strings.Builder
+	line := func(content string) {
+		b.WriteString(icalFold(content))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//foodapp//Meal plan//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + icalEscape(name))
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + event.Modified.UTC().Format(icalUTCLayout))
+		line("LAST-MODIFIED:" + event.Modified.UTC().Format(icalUTCLayout))
+		line("DTSTART:" + event.Start.Format(icalFloatingLayout))
+		line("DURATION:" + icalDuration(event.Duration))
+		line("SUMMARY:" + icalEscape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + icalEscape(event.Description))
+		}
+		if event.URL != "" {
+			line("URL:" + event.URL)
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// icalEscape escapes the characters TEXT values reserve.
+func icalEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// icalFold splits a content line longer than 75 octets into continuation
+// lines, never inside a UTF-8 character.
+func icalFold(content string) string {
+	if len(content) <= icalLineOctets {
+		return content
+	}
+
+	var b strings.Builder
+	limit := icalLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = icalLineOctets - 1
+	}
+	b.WriteString(content)
+	return b.String()
+}
+
+// icalDuration writes d as an RFC 5545 duration, e.g. PT1H30M.
+func icalDuration(d time.Duration) string {
+	if d <= 0 {
+		return "PT0S"
+	}
+
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	duration := "PT"
+	if hours > 0 {
+		duration += fmt.Sprintf("%dH", hours)
+	}
+	if minutes > 0 || hours == 0 {
+		duration += fmt.Sprintf("%dM", minutes)
+	}
+	return duration
+}
@@ -0,0 +1,68 @@
+package utils
+
+import "strings"
+
+// irregularPlurals covers food words the suffix rules below get wrong.
+var irregularPlurals = map[string]string{
+	"leaves":   "leaf",
+	"loaves":   "loaf",
+	"halves":   "half",
+	"knives":   "knife",
+	"potatoes": "potato",
+	"tomatoes": "tomato",
+	"mangoes":  "mango",
+	"cookies":  "cookie",
+	"pies":     "pie",
+}
+
+// uncountable words end in "s" but are not plurals.
+var uncountable = map[string]bool{
+	"asparagus": true,
+	"couscous":  true,
+	"hummus":    true,
+	"molasses":  true,
+	"swiss":     true,
+	"citrus":    true,
+	"octopus":   true,
+	"series":    true,
+	"species":   true,
+}
+
+// NormalizeIngredientName folds case, whitespace and simple English plurals so
+// "Tomatoes", " tomato " and "TOMATO" compare equal.
+func NormalizeIngredientName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) == 0 {
+		return ""
+	}
+
+	last := len(words) - 1
+	words[last] = singularize(words[last])
+	return strings.Join(words, " ")
+}
+
+func singularize(word string) string {
+	if singular, ok := irregularPlurals[word]; ok {
+		return singular
+	}
+	if uncountable[word] || len(word) <= 3 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "oes"),
+		strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
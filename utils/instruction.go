@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// stepPrefix matches leading numbering such as "1.", "2)", "Step 3:" or "- ".
+	stepPrefix = regexp.MustCompile(`^\s*(?i:step\s*)?(\d+\s*[.):-]|[-*•])\s*`)
+	// inlineStep finds numbered steps written on a single line: "1. Boil 2. Drain".
+	inlineStep = regexp.MustCompile(`(?:^|\s)\d+[.)]\s`)
+)
+
+// SplitInstruction turns a free-text instruction into ordered step texts.
+// Each non-empty line becomes a step; a single line with inline numbering is
+// split on the numbers instead.
+func SplitInstruction(instruction string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(instruction, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 1 {
+		if idx := inlineStep.FindAllStringIndex(lines[0], -1); len(idx) > 1 {
+			var parts []string
+			for i, loc := range idx {
+				end := len(lines[0])
+				if i+1 < len(idx) {
+					end = idx[i+1][0]
+				}
+				parts = append(parts, lines[0][loc[0]:end])
+			}
+			if prefix := strings.TrimSpace(lines[0][:idx[0][0]]); prefix != "" {
+				parts = append([]string{prefix}, parts...)
+			}
+			lines = parts
+		}
+	}
+
+	var steps []string
+	for _, line := range lines {
+		text := strings.TrimSpace(stepPrefix.ReplaceAllString(line, ""))
+		if text != "" {
+			steps = append(steps, text)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDFLine is one line of text in a document made by TextPDF.
+type PDFLine struct {
+	Text    string
+	Heading bool
+}
+
+const (
+	pdfPageWidth  = 595 // A4 in points
+	pdfPageHeight = 842
+	pdfMargin     = 56
+
+	pdfTitleSize   = 18
+	pdfHeadingSize = 13
+	pdfTextSize    = 11
+)
+
+// TextPDF lays out a title and lines of text on as many A4 pages as needed,
+// using the standard Helvetica fonts so nothing has to be embedded. The output
+// has no timestamps: the same input always gives the same bytes. Characters
+// outside Latin-1 are printed as "?".
+func TextPDF(title string, lines []PDFLine) []byte {
+	var pages []string
+	var page strings.Builder
+	y := pdfPageHeight - pdfMargin
+
+	write := func(font string, size int, text string) {
+		fmt.Fprintf(&page, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, pdfMargin, y, pdfEscape(text))
+	}
+
+	y -= pdfTitleSize
+	write("F2", pdfTitleSize, title)
+	y -= pdfTitleSize / 2
+
+	for _, line := range lines {
+		font, size, gap := "F1", pdfTextSize, 5
+		if line.Heading {
+			font, size, gap = "F2", pdfHeadingSize, 11
+		}
+
+		if y-gap-size < pdfMargin {
+			pages = append(pages, page.String())
+			page.Reset()
+			y = pdfPageHeight - pdfMargin
+			gap = 0
+		}
+
+		y -= gap + size
+		write(font, size, line.Text)
+	}
+	pages = append(pages, page.String())
+
+	// Objects 1-5 are the catalog, the page tree, the two fonts and the
+	// info dictionary; each page then takes a page object and its content.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, filled in below
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (foodapp) >>", pdfEscape(title)),
+	}
+
+	var kids []string
+	for _, content := range pages {
+		pageID := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfEscape turns text into the body of a PDF string literal in
+// WinAnsiEncoding.
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+// countUnits are units that describe whole items, so scaled quantities
+// must stay whole numbers (nobody buys 1.3 eggs).
+var countUnits = map[string]bool{
+	"pc":      true,
+	"pcs":     true,
+	"piece":   true,
+	"pieces":  true,
+	"item":    true,
+	"items":   true,
+	"egg":     true,
+	"eggs":    true,
+	"clove":   true,
+	"cloves":  true,
+	"slice":   true,
+	"slices":  true,
+	"can":     true,
+	"cans":    true,
+	"bunch":   true,
+	"bunches": true,
+	"pack":    true,
+	"packs":   true,
+}
+
+func IsCountUnit(unit string) bool {
+	return countUnits[strings.ToLower(strings.TrimSpace(unit))]
+}
+
+// ScaleQuantity multiplies quantity by factor. Count-based units are rounded
+// to the nearest whole item and never drop below one; everything else is
+// rounded to two decimals.
+func ScaleQuantity(quantity float64, unit string, factor float64) float64 {
+	scaled := quantity * factor
+	if IsCountUnit(unit) {
+		rounded := math.Round(scaled)
+		if rounded < 1 && scaled > 0 {
+			return 1
+		}
+		return rounded
+	}
+	return RoundTo(scaled, 2)
+}
+
+func RoundTo(value float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(value*pow) / pow
+}
+
+// massUnits and volumeUnits give the size of each measured unit in grams and
+// millilitres respectively.
+var massUnits = map[string]float64{
+	"mg":    0.001,
+	"g":     1,
+	"gram":  1,
+	"grams": 1,
+	"kg":    1000,
+	"oz":    28.3495,
+	"lb":    453.592,
+	"lbs":   453.592,
+}
+
+var volumeUnits = map[string]float64{
+	"ml":   1,
+	"cl":   10,
+	"dl":   100,
+	"l":    1000,
+	"tsp":  4.92892,
+	"tbsp": 14.7868,
+	"cup":  240,
+	"cups": 240,
+}
+
+// unitSize returns the dimension of a unit (mass, volume or count) and its
+// size in that dimension's base unit. A missing unit counts items.
+func unitSize(unit string) (dimension string, size float64, ok bool) {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if size, ok := massUnits[unit]; ok {
+		return "mass", size, true
+	}
+	if size, ok := volumeUnits[unit]; ok {
+		return "volume", size, true
+	}
+	if unit == "" || countUnits[unit] {
+		return "count", 1, true
+	}
+	return "", 0, false
+}
+
+// ConvertQuantity converts quantity from one unit into another of the same
+// dimension, e.g. 500 g into 0.5 kg. ok is false when the units don't measure
+// the same thing. Unknown units only convert into themselves.
+func ConvertQuantity(quantity float64, from, to string) (converted float64, ok bool) {
+	if strings.EqualFold(strings.TrimSpace(from), strings.TrimSpace(to)) {
+		return quantity, true
+	}
+
+	fromDimension, fromSize, fromOK := unitSize(from)
+	toDimension, toSize, toOK := unitSize(to)
+	if !fromOK || !toOK || fromDimension != toDimension {
+		return 0, false
+	}
+	return quantity * fromSize / toSize, true
+}
+
+// BaseUnit is the unit that whole amounts of unit are best counted in when
+// unit itself is too coarse: grams for mass and millilitres for volume units
+// larger than those. Any other unit is returned unchanged.
+func BaseUnit(unit string) string {
+	dimension, size, ok := unitSize(unit)
+	if !ok || size <= 1 {
+		return unit
+	}
+	switch dimension {
+	case "mass":
+		return "g"
+	case "volume":
+		return "ml"
+	}
+	return unit
+}